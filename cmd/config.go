@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/hanthor/bluefin-cli/internal/config"
+	"github.com/hanthor/bluefin-cli/internal/motd"
+	"github.com/hanthor/bluefin-cli/internal/policy"
+	"github.com/hanthor/bluefin-cli/internal/shell"
+	"github.com/hanthor/bluefin-cli/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show effective settings and where they come from",
+	Long: `Show the effective value of each setting and its source: the built-in default, your
+config files, the environment, or the organization policy in /etc/bluefin-cli/policy.json
+or $HOMEBREW_PREFIX/etc/bluefin-cli/policy.json.
+
+Keys are "<section>.<key>", where the section is config (config.json), shell (shell.json)
+or motd (motd.json), e.g. shell.atuin or motd.tips-directory.`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Show the effective value of a setting",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		values, err := effectiveSettings()
+		if err != nil {
+			return err
+		}
+		for _, v := range values {
+			if v.Key == args[0] {
+				if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+					return encodeJSON(v)
+				}
+				fmt.Printf("%s %s\n", v.String(), tui.InfoStyle.Render("("+v.Source+")"))
+				return nil
+			}
+		}
+		return fmt.Errorf("unknown setting %q; see 'bluefin-cli config list'", args[0])
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List every setting with its effective value and source",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		values, err := effectiveSettings()
+		if err != nil {
+			return err
+		}
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			return encodeJSON(values)
+		}
+
+		for _, v := range values {
+			fmt.Printf("%-30s %-40s %s\n", v.Key, v.String(), tui.InfoStyle.Render(v.Source))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configListCmd)
+
+	configGetCmd.Flags().Bool("json", false, "Print the value and its source as JSON")
+	configListCmd.Flags().Bool("json", false, "Print the settings as JSON")
+}
+
+// effectiveSettings resolves the settings of config.json, shell.json and motd.json, sorted by key
+func effectiveSettings() ([]policy.Value, error) {
+	if _, err := policy.Load(); err != nil {
+		fmt.Fprintln(os.Stderr, tui.WarningStyle.Render(fmt.Sprintf("Warning: %v", err)))
+	}
+
+	var values []policy.Value
+	for _, resolve := range []func() ([]policy.Value, error){
+		config.Effective,
+		func() ([]policy.Value, error) { return shell.Effective(currentShellName()) },
+		motd.Effective,
+	} {
+		v, err := resolve()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v...)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].Key < values[j].Key })
+	return values, nil
+}
+
+func encodeJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
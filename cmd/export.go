@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/hanthor/bluefin-cli/internal/install"
+	"github.com/hanthor/bluefin-cli/internal/profile"
+	"github.com/hanthor/bluefin-cli/internal/shell"
+	"github.com/hanthor/bluefin-cli/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: "Export this machine's setup as a portable profile",
+	Long: `Write a profile file containing a Brewfile of the installed formulae, casks and flatpaks,
+annotated with the bundle each came from, plus the shell tools, MOTD config and Starship
+config. Apply it on another machine with 'bluefin-cli import'.
+
+The profile is written to bluefin-profile.json unless a file is given; use - for stdout.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := "bluefin-profile.json"
+		if len(args) > 0 {
+			path = args[0]
+		}
+
+		p, err := profile.Current(currentShellName())
+		if err != nil {
+			return err
+		}
+		if hostname, err := os.Hostname(); err == nil {
+			p.Name = hostname
+		}
+
+		if noPackages, _ := cmd.Flags().GetBool("no-packages"); !noPackages {
+			fmt.Fprintln(os.Stderr, tui.InfoStyle.Render("Listing installed packages..."))
+			var brewfile strings.Builder
+			if err := install.ExportBrewfile(&brewfile); err != nil {
+				return err
+			}
+			p.Brewfile = brewfile.String()
+		}
+
+		if path == "-" {
+			return profile.Encode(os.Stdout, p)
+		}
+		if err := profile.Write(path, p); err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, tui.SuccessStyle.Render(fmt.Sprintf("✓ Profile written to %s", path)))
+		return nil
+	},
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Apply a profile exported on another machine",
+	Long: `Install the packages of a profile written by 'bluefin-cli export', then apply its shell
+tools, MOTD config and Starship config.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, err := profile.Read(args[0])
+		if err != nil {
+			return err
+		}
+
+		noPackages, _ := cmd.Flags().GetBool("no-packages")
+		if p.Brewfile == "" {
+			noPackages = true
+		}
+
+		printProfileSummary(p, !noPackages)
+
+		if yes, _ := cmd.Flags().GetBool("yes"); !yes {
+			var confirm bool
+			if err := huh.NewConfirm().
+				Title("Apply this profile? Your current shell, MOTD and Starship settings will be replaced.").
+				Value(&confirm).
+				WithTheme(tui.AppTheme).
+				Run(); err != nil || !confirm {
+				fmt.Println(tui.InfoStyle.Render("Nothing changed"))
+				return nil
+			}
+		}
+
+		if !noPackages {
+			brewfilePath, cleanup, err := install.WriteTempBrewfile("Brewfile", p.Brewfile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			// Record each package against the bundle it was annotated with on export
+			files, cleanupFiles, err := install.SplitExport(p.Brewfile, install.BundleName(args[0]))
+			if err != nil {
+				return err
+			}
+			defer cleanupFiles()
+
+			headless, _ := cmd.Flags().GetBool("headless")
+			if err := install.InstallBrewfile(brewfilePath, files, headless); err != nil {
+				return err
+			}
+		}
+
+		shell.InstallTools(&p.Shell)
+		if err := p.Apply(); err != nil {
+			return err
+		}
+
+		fmt.Println(tui.SuccessStyle.Render("✓ Profile applied. Restart your shell to pick up the new settings."))
+		return nil
+	},
+}
+
+func printProfileSummary(p *profile.Profile, withPackages bool) {
+	title := "Profile"
+	if p.Name != "" {
+		title += " from " + p.Name
+	}
+	fmt.Println(tui.TitleStyle.Render(title))
+
+	var enabled []string
+	for _, tool := range shell.Tools {
+		if p.Shell.IsEnabled(tool.Name) {
+			enabled = append(enabled, tool.Name)
+		}
+	}
+	if p.Shell.IsEnabled("motd") {
+		enabled = append(enabled, "MOTD")
+	}
+	fmt.Printf("  Shell tools:     %s\n", strings.Join(enabled, ", "))
+	fmt.Printf("  MOTD theme:      %s\n", p.Motd.DefaultTheme)
+	if p.StarshipPreset != "" {
+		fmt.Printf("  Starship preset: %s\n", p.StarshipPreset)
+	} else if p.StarshipConfig != "" {
+		fmt.Println("  Starship preset: custom starship.toml")
+	}
+	if withPackages {
+		entries, _ := install.ParseBrewfileString(p.Brewfile)
+		packages := 0
+		for _, e := range entries {
+			if e.IsPackage() {
+				packages++
+			}
+		}
+		fmt.Printf("  Packages:        %d\n", packages)
+	}
+	fmt.Println()
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(importCmd)
+
+	exportCmd.Flags().Bool("no-packages", false, "Leave the Brewfile out of the profile")
+	importCmd.Flags().Bool("no-packages", false, "Apply only the settings, without installing packages")
+	importCmd.Flags().Bool("headless", false, "Install packages with brew bundle instead of opening bbrew")
+	importCmd.Flags().BoolP("yes", "y", false, "Apply without asking for confirmation")
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/hanthor/bluefin-cli/internal/ledger"
+	"github.com/hanthor/bluefin-cli/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show what bluefin-cli installed and removed",
+	Long: `Show the install ledger: every package bluefin-cli installed or removed, with the
+backend, version, the bundle or tool it was installed for, and the command that did it.
+
+  bluefin-cli history --bundle k8s
+  bluefin-cli history --since 2025-01-01 --until 2025-01-31
+  bluefin-cli history --since 7d --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		query, err := historyQuery(cmd)
+		if err != nil {
+			return err
+		}
+
+		entries, err := ledger.Read()
+		if err != nil {
+			return err
+		}
+		entries = ledger.Filter(entries, query.Match)
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if entries == nil {
+				entries = []ledger.Entry{}
+			}
+			return enc.Encode(entries)
+		}
+
+		if len(entries) == 0 {
+			fmt.Println(tui.InfoStyle.Render("No matching history"))
+			return nil
+		}
+		for _, e := range entries {
+			origin := e.Bundle
+			if e.Tool != "" {
+				origin = "tool " + e.Tool
+			} else if origin != "" {
+				origin = "bundle " + origin
+			}
+			action := tui.SuccessStyle.Render(fmt.Sprintf("%-7s", e.Action))
+			if e.Action == ledger.ActionRemove {
+				action = tui.WarningStyle.Render(fmt.Sprintf("%-7s", e.Action))
+			}
+			fmt.Printf("%s  %s  %-32s %-12s %-14s %-22s %s\n",
+				e.Time.Local().Format("2006-01-02 15:04"), action, e.Package, e.Version, e.Backend, origin, e.Command)
+		}
+		return nil
+	},
+}
+
+func historyQuery(cmd *cobra.Command) (ledger.Query, error) {
+	var q ledger.Query
+	q.Bundle, _ = cmd.Flags().GetString("bundle")
+	q.Tool, _ = cmd.Flags().GetString("tool")
+
+	if since, _ := cmd.Flags().GetString("since"); since != "" {
+		t, err := ledger.ParseTime(since, false)
+		if err != nil {
+			return q, err
+		}
+		q.Since = t
+	}
+	if until, _ := cmd.Flags().GetString("until"); until != "" {
+		t, err := ledger.ParseTime(until, true)
+		if err != nil {
+			return q, err
+		}
+		q.Until = t
+	}
+	return q, nil
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().String("bundle", "", "Only show packages installed for this bundle (e.g. k8s, wallpapers)")
+	historyCmd.Flags().String("tool", "", "Only show packages installed for this shell tool")
+	historyCmd.Flags().String("since", "", "Only show entries from this date (YYYY-MM-DD) or age (7d, 12h)")
+	historyCmd.Flags().String("until", "", "Only show entries up to and including this date")
+	historyCmd.Flags().Bool("json", false, "Print entries as JSON")
+}
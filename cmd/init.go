@@ -57,19 +57,24 @@ Fish (~/.config/fish/config.fish):
 		fmt.Println(script)
 		fmt.Println()
 
+		motdEnabled := config.MotdEnabled(shellName)
+		if cmd.Flags().Changed("motd") {
+			motdEnabled = *toolFlags["motd"]
+		}
+
 		// Add MOTD hook if enabled in config
-		if config.IsEnabled("Motd") {
+		if motdEnabled {
 			switch shellName {
 			case "bash", "zsh":
 				// Only run MOTD if interactive
 				fmt.Println(`# bluefin-cli motd hook
 if [ -n "$PS1" ] && [ -t 1 ]; then
-    bluefin-cli motd show
+    bluefin-cli motd show --startup
 fi`)
 			case "fish":
 				fmt.Println(`# bluefin-cli motd hook
 if status is-interactive
-    bluefin-cli motd show
+    bluefin-cli motd show --startup
 end`)
 			default:
 				return fmt.Errorf("unsupported shell: %s", shellName)
@@ -1,13 +1,17 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
 	"github.com/hanthor/bluefin-cli/internal/install"
+	"github.com/hanthor/bluefin-cli/internal/ledger"
 	"github.com/hanthor/bluefin-cli/internal/tui"
 )
 
@@ -32,7 +36,8 @@ Or provide a path to a local Brewfile.`,
 			return runBundlesMenu()
 		}
 
-		return install.Bundle(args[0])
+		headless, _ := cmd.Flags().GetBool("headless")
+		return install.Bundle(args[0], headless)
 	},
 }
 
@@ -50,19 +55,318 @@ func init() {
 	rootCmd.AddCommand(installCmd)
 	installCmd.AddCommand(installListCmd)
 	installCmd.AddCommand(installWallpapersCmd)
+	installCmd.AddCommand(installFlatpakCmd)
+	installCmd.AddCommand(installRemoveCmd)
+	installCmd.AddCommand(installStatusCmd)
+	installCmd.AddCommand(installDiffCmd)
+	installCmd.AddCommand(installCacheCmd)
+	installCacheCmd.AddCommand(installCacheListCmd)
+	installCacheCmd.AddCommand(installCacheClearCmd)
+	installCacheCmd.AddCommand(installCacheRefreshCmd)
+	installFlatpakCmd.AddCommand(installFlatpakListCmd)
+	installFlatpakCmd.AddCommand(installFlatpakUpdateCmd)
+
+	installCmd.Flags().Bool("headless", false, "Install everything with brew bundle instead of opening bbrew")
+	installFlatpakCmd.PersistentFlags().String("scope", "", "Flatpak installation to use: user or system (default from config)")
+	installRemoveCmd.Flags().BoolP("yes", "y", false, "Remove without asking for confirmation")
+	installStatusCmd.Flags().Bool("json", false, "Print the report as JSON")
+	installDiffCmd.Flags().Bool("json", false, "Print the diff as JSON")
+	installDiffCmd.Flags().Bool("install-added", false, "Install the entries added since the last install")
+	installDiffCmd.Flags().Bool("headless", false, "Install the additions with brew bundle instead of opening bbrew")
+}
+
+var installCacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage cached bundle Brewfiles",
+	Long: `Downloaded Brewfiles are cached under $XDG_CACHE_HOME/bluefin-cli/bundles, revalidated
+on each use and used as is when offline.`,
+}
+
+var installCacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List cached Brewfiles",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := install.CachedBundles()
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			fmt.Println(tui.InfoStyle.Render("The bundle cache is empty"))
+			return nil
+		}
+		for _, e := range entries {
+			fmt.Printf("  %-28s %8s  fetched %s  sha256 %s\n",
+				e.File, humanize.Bytes(uint64(e.Size)), humanize.Time(e.Fetched), e.SHA256[:12])
+		}
+		return nil
+	},
+}
+
+var installCacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete all cached Brewfiles",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := install.ClearCache(); err != nil {
+			return err
+		}
+		fmt.Println(tui.SuccessStyle.Render("✓ Bundle cache cleared"))
+		return nil
+	},
+}
+
+var installCacheRefreshCmd = &cobra.Command{
+	Use:   "refresh [bundle...]",
+	Short: "Download or revalidate cached Brewfiles",
+	Long:  `Revalidate the given bundles, or every bundle when none are given, so they are available offline.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return install.RefreshCache(args)
+	},
+}
+
+var installStatusCmd = &cobra.Command{
+	Use:   "status <bundle|Brewfile>...",
+	Short: "Show how much of a bundle is installed",
+	Long: `Compare the formulae, casks and flatpaks of one or more bundles with what is installed,
+reporting each item as installed, missing or outdated.
+
+  bluefin-cli install status k8s cli
+  bluefin-cli install status k8s --json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var reports []*install.BundleStatus
+		for _, bundle := range args {
+			report, err := install.CheckBundle(bundle, true)
+			if err != nil {
+				return err
+			}
+			reports = append(reports, report)
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(reports)
+		}
+
+		for _, report := range reports {
+			printBundleStatus(report)
+		}
+		return nil
+	},
+}
+
+func printBundleStatus(report *install.BundleStatus) {
+	fmt.Println(tui.TitleStyle.Render(fmt.Sprintf("%s: %d%% installed", report.Bundle, report.Percent())))
+	fmt.Printf("  %d installed, %d outdated, %d missing\n\n",
+		report.Count(install.StateInstalled), report.Count(install.StateOutdated), report.Count(install.StateMissing))
+
+	for _, item := range report.Items {
+		var state string
+		switch item.State {
+		case install.StateInstalled:
+			state = tui.SuccessStyle.Render(fmt.Sprintf("✓ %-9s", item.State))
+		case install.StateOutdated:
+			state = tui.WarningStyle.Render(fmt.Sprintf("↑ %-9s", item.State))
+		default:
+			state = tui.ErrorStyle.Render(fmt.Sprintf("✗ %-9s", item.State))
+		}
+
+		version := item.InstalledVersion
+		if item.LatestVersion != "" {
+			version += " → " + item.LatestVersion
+		}
+		fmt.Printf("  %s %-8s %-45s %s\n", state, item.Kind, item.Name, version)
+	}
+	fmt.Println()
+}
+
+var installDiffCmd = &cobra.Command{
+	Use:   "diff <bundle|Brewfile>",
+	Short: "Show what changed in a bundle since it was installed",
+	Long: `Fetch the current version of a bundle and compare it with the snapshot taken when it
+was last installed, listing added, removed and changed entries.
+
+  bluefin-cli install diff k8s
+  bluefin-cli install diff k8s --install-added`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		snap, diff, err := install.DiffBundle(args[0])
+		if err != nil {
+			return err
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(diff); err != nil {
+				return err
+			}
+		} else {
+			printBundleDiff(snap, diff)
+		}
+
+		if add, _ := cmd.Flags().GetBool("install-added"); add {
+			headless, _ := cmd.Flags().GetBool("headless")
+			return install.InstallAdditions(args[0], diff, headless)
+		}
+		return nil
+	},
+}
+
+func printBundleDiff(snap *install.BundleSnapshot, diff install.BundleDiff) {
+	fmt.Println(tui.TitleStyle.Render(fmt.Sprintf("%s: changes since %s", snap.Bundle, snap.Time.Local().Format("2006-01-02 15:04"))))
+	if diff.Empty() {
+		fmt.Println(tui.SuccessStyle.Render("  ✓ No changes"))
+		fmt.Println()
+		return
+	}
+
+	for _, e := range diff.Added {
+		fmt.Println(tui.SuccessStyle.Render("  + " + e.String()))
+	}
+	for _, e := range diff.Removed {
+		fmt.Println(tui.ErrorStyle.Render("  - " + e.String()))
+	}
+	for _, c := range diff.Changed {
+		fmt.Println(tui.WarningStyle.Render("  ~ " + c.Old.String()))
+		fmt.Println(tui.WarningStyle.Render("    → " + c.New.String()))
+	}
+	fmt.Printf("\n  %d added, %d removed, %d changed\n\n", len(diff.Added), len(diff.Removed), len(diff.Changed))
+}
+
+var installRemoveCmd = &cobra.Command{
+	Use:   "remove <bundle>",
+	Short: "Uninstall the packages a bundle installed",
+	Long: `Uninstall the formulae, casks and flatpaks that bluefin-cli installed for a bundle.
+
+Only packages recorded in the install ledger are removed; anything that was already
+installed before the bundle, or installed by other means, is left alone.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		installed, err := ledger.Installed()
+		if err != nil {
+			return err
+		}
+		bundle := install.BundleName(args[0])
+		entries := ledger.Filter(installed, func(e ledger.Entry) bool { return e.Bundle == bundle })
+
+		yes, _ := cmd.Flags().GetBool("yes")
+		return removeRecorded(entries, fmt.Sprintf("bundle %s", bundle), yes)
+	},
+}
+
+// removeRecorded lists the ledger entries, asks for confirmation unless yes is set, and uninstalls them
+func removeRecorded(entries []ledger.Entry, what string, yes bool) error {
+	if len(entries) == 0 {
+		fmt.Println(tui.InfoStyle.Render(fmt.Sprintf("Nothing to remove: bluefin-cli has no record of installing packages for %s", what)))
+		return nil
+	}
+
+	fmt.Println(tui.InfoStyle.Render(fmt.Sprintf("The following packages were installed by bluefin-cli for %s:", what)))
+	for _, e := range entries {
+		detail := e.Backend
+		if e.Kind != "" {
+			detail += ", " + e.Kind
+		}
+		fmt.Printf("  %s (%s)\n", e.Package, detail)
+	}
+	fmt.Println()
+
+	if !yes {
+		var confirm bool
+		if err := huh.NewConfirm().
+			Title(fmt.Sprintf("Uninstall %d package(s)?", len(entries))).
+			Value(&confirm).
+			WithTheme(tui.AppTheme).
+			Run(); err != nil || !confirm {
+			fmt.Println(tui.InfoStyle.Render("Nothing removed"))
+			return nil
+		}
+	}
+
+	if err := ledger.Uninstall(entries); err != nil {
+		return fmt.Errorf("failed to remove packages: %w", err)
+	}
+	fmt.Println(tui.SuccessStyle.Render(fmt.Sprintf("✓ Removed %d package(s)", len(entries))))
+	return nil
+}
+
+var installFlatpakCmd = &cobra.Command{
+	Use:   "flatpak [app-id...]",
+	Short: "Install flatpak apps from Flathub",
+	Long: `Install flatpak applications from Flathub by app ID, e.g.
+
+  bluefin-cli install flatpak org.gnome.Boxes
+  bluefin-cli install flatpak --scope system com.mattjakeman.ExtensionManager`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		scope, err := flatpakScopeFlag(cmd)
+		if err != nil {
+			return err
+		}
+		return install.InstallFlatpaks(args, scope)
+	},
+}
+
+var installFlatpakListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List installed flatpak apps",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		apps, err := install.ListFlatpaks()
+		if err != nil {
+			return err
+		}
+		if len(apps) == 0 {
+			fmt.Println(tui.InfoStyle.Render("No flatpak apps installed"))
+			return nil
+		}
+
+		scopeFilter, _ := cmd.Flags().GetString("scope")
+		for _, app := range apps {
+			if scopeFilter != "" && string(app.Scope) != scopeFilter {
+				continue
+			}
+			fmt.Printf("  %-45s %-12s %-8s %s\n", app.ID, app.Version, app.Scope, app.Name)
+		}
+		return nil
+	},
+}
+
+var installFlatpakUpdateCmd = &cobra.Command{
+	Use:   "update [app-id...]",
+	Short: "Update flatpak apps",
+	Long:  `Update the given flatpak apps, or every app in the selected scope when none are given.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		scope, err := flatpakScopeFlag(cmd)
+		if err != nil {
+			return err
+		}
+		return install.UpdateFlatpaks(args, scope)
+	},
+}
+
+func flatpakScopeFlag(cmd *cobra.Command) (install.FlatpakScope, error) {
+	value, _ := cmd.Flags().GetString("scope")
+	if value == "" {
+		return install.DefaultFlatpakScope(), nil
+	}
+	return install.ParseFlatpakScope(value)
 }
 
 var installWallpapersCmd = &cobra.Command{
 	Use:   "wallpapers [cask...]",
-	Short: "Install wallpaper casks from ublue-os/tap",
-	Long:  "Install wallpapers published as Homebrew casks from the ublue-os/tap tap.",
+	Short: "Install wallpaper casks from the configured taps",
+	Long: `Install wallpapers published as Homebrew casks.
+
+Casks are discovered in the taps listed under "wallpaper-taps" in config.json
+(default: ublue-os/tap).`,
 	Args:  cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) > 0 {
 			return install.InstallWallpaperCasks(args)
 		}
 
-		return runWallpapersMenu()
+		return runWallpaperInstallMenu()
 	},
 }
 
@@ -89,6 +393,14 @@ func runBundlesMenu() error {
 			opts = append(opts, huh.NewOption("🖥️  Full GNOME Desktop", "full-desktop"))
 		}
 
+		var allowed []huh.Option[string]
+		for _, opt := range opts {
+			if install.BundleAllowed(opt.Value) {
+				allowed = append(allowed, opt)
+			}
+		}
+		opts = allowed
+
 		form := huh.NewForm(
 			huh.NewGroup(
 				huh.NewMultiSelect[string]().
@@ -124,6 +436,7 @@ func runBundlesMenu() error {
 	}
 
 	var brewfiles []string
+	var files []install.BundleFile
 	var cleanups []func()
 
 	defer func() {
@@ -138,69 +451,99 @@ func runBundlesMenu() error {
 			return err
 		}
 		brewfiles = append(brewfiles, path)
+		files = append(files, install.BundleFile{Name: bundle, Path: path})
 		cleanups = append(cleanups, cleanup)
 	}
 
-	if len(brewfiles) > 0 {
-		if err := install.EnsureBbrew(); err != nil {
-			return err
-		}
-
-		var finalPath string
-		if len(brewfiles) > 1 {
-			mergedPath, cleanup, err := install.MergeBrewfiles(brewfiles)
-			if err != nil {
-				return err
-			}
-			cleanups = append(cleanups, cleanup)
-			finalPath = mergedPath
-			fmt.Println(tui.InfoStyle.Render("🍺 Merged Brewfiles into single view..."))
-		} else {
-			finalPath = brewfiles[0]
-		}
+	if len(brewfiles) == 0 {
+		return nil
+	}
 
-		fmt.Println(tui.InfoStyle.Render(fmt.Sprintf("🍺 Opening apps in bbrew...")))
-		if err := install.RunBbrew(finalPath); err != nil {
+	finalPath := brewfiles[0]
+	if len(brewfiles) > 1 {
+		mergedPath, cleanup, err := install.MergeBrewfiles(brewfiles)
+		if err != nil {
 			return err
 		}
+		cleanups = append(cleanups, cleanup)
+		finalPath = mergedPath
+		fmt.Println(tui.InfoStyle.Render("🍺 Merged Brewfiles into single view..."))
 	}
 
-	return nil
+	selectedPath, headless, cleanup, err := previewBundlePackages(finalPath)
+	if err != nil || selectedPath == "" {
+		return err
+	}
+	cleanups = append(cleanups, cleanup)
+
+	return install.InstallBrewfile(selectedPath, files, headless)
 }
 
-func runWallpapersMenu() error {
-	tui.ClearScreen()
-	tui.RenderHeader("Bluefin CLI", "Main Menu > Wallpapers")
-	casks, err := install.GetWallpaperCasks()
+// previewBundlePackages lists every package of a Brewfile with its description and lets the user
+// deselect packages and pick the installer. It returns the path of the filtered Brewfile, or ""
+// when the user backs out.
+func previewBundlePackages(brewfilePath string) (string, bool, func(), error) {
+	entries, err := install.ParseBrewfile(brewfilePath)
 	if err != nil {
-		return fmt.Errorf("failed to discover wallpaper casks: %w", err)
-	}
-	if len(casks) == 0 {
-		return fmt.Errorf("no wallpaper casks found in ublue-os/tap")
+		return "", false, func() {}, err
 	}
 
-	opts := make([]huh.Option[string], 0, len(casks))
-	for _, c := range casks {
-		opts = append(opts, huh.NewOption(c, c))
-	}
+	fmt.Println(tui.InfoStyle.Render("🔍 Looking up package descriptions..."))
+	packages := install.DescribeEntries(entries)
 
+	opts := make([]huh.Option[string], 0, len(packages))
 	var selected []string
+	for _, p := range packages {
+		key := p.Entry.Kind + ":" + p.Entry.Name
+		label := fmt.Sprintf("%-7s %s", p.Entry.Kind, p.Entry.Name)
+		if p.Desc != "" {
+			label += " – " + p.Desc
+		}
+		if p.Installed {
+			label += " ✓"
+		}
+		opts = append(opts, huh.NewOption(label, key))
+		selected = append(selected, key)
+	}
+
+	headless := false
+	tui.ClearScreen()
+	tui.RenderHeader("Bluefin CLI", "Main Menu > Install Apps > Preview")
 	form := huh.NewForm(
 		huh.NewGroup(
 			huh.NewMultiSelect[string]().
-				Title("Select wallpapers to install (space to select, enter to confirm)").
+				Title(fmt.Sprintf("%d packages in the selected bundles (deselect any you don't want)", len(packages))).
+				Description("✓ marks packages that are already installed").
 				Options(opts...).
+				Height(20).
 				Value(&selected),
+			huh.NewSelect[bool]().
+				Title("How do you want to install them?").
+				Options(
+					huh.NewOption("Review in bbrew", false),
+					huh.NewOption("Install now without prompting (brew bundle)", true),
+				).
+				Value(&headless),
 		),
 	).WithTheme(tui.AppTheme).WithKeyMap(tui.MenuKeyMap())
 	if err := form.Run(); err != nil {
 		if err == huh.ErrUserAborted {
-			return nil
+			return "", false, func() {}, nil
 		}
-		return fmt.Errorf("form error: %w", err)
+		return "", false, func() {}, fmt.Errorf("form error: %w", err)
 	}
-	if len(selected) == 0 {
-		return fmt.Errorf("no wallpapers selected")
+
+	keep := make(map[string]bool)
+	for _, key := range selected {
+		keep[key] = true
 	}
-	return install.InstallWallpaperCasks(selected)
-}
+	if len(keep) == 0 {
+		fmt.Println(tui.InfoStyle.Render("No packages selected"))
+		return "", false, func() {}, nil
+	}
+
+	path, cleanup, err := install.FilterBrewfile(brewfilePath, func(e install.BrewfileEntry) bool {
+		return keep[e.Kind+":"+e.Name]
+	})
+	return path, headless, cleanup, err
+}
\ No newline at end of file
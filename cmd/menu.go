@@ -38,6 +38,7 @@ var menuCmd = &cobra.Command{
 				huh.NewOption("📦 Install Apps ❯", "bundles"),
 				huh.NewOption("🖼  Wallpapers ❯", "wallpapers"),
 				huh.NewOption("🚀 Starship Theme ❯", "starship"),
+				huh.NewOption("👤 Profiles ❯", "profiles"),
 			}
 			opts = append(opts, huh.NewOption("Exit", "exit"))
 
@@ -79,6 +80,10 @@ var menuCmd = &cobra.Command{
 				if err := runStarshipMenu(); err != nil {
 					return err
 				}
+			case "profiles":
+				if err := runProfileMenu(); err != nil {
+					return err
+				}
 			case "exit":
 				return nil
 			}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/charmbracelet/huh"
 	"github.com/spf13/cobra"
@@ -24,8 +25,13 @@ var motdCmd = &cobra.Command{
 var motdToggleCmd = &cobra.Command{
 	Use:   "toggle [shell|all] [on|off]",
 	Short: "Toggle MOTD for shells",
-	Long:  `Enable or disable MOTD display on shell startup for bash, zsh, fish, or all shells.`,
-	Args:  cobra.MaximumNArgs(2),
+	Long: `Enable or disable MOTD display on shell startup for bash, zsh, fish, or all shells.
+
+The setting is saved in shell.json ("motd", or "motd-<shell>" for one shell) and takes effect
+in new shells that run 'bluefin-cli init', see 'bluefin-cli shell'. MOTD lines added to the
+shell startup files by older versions are removed.`,
+	Args:      cobra.MaximumNArgs(2),
+	ValidArgs: []string{"bash", "zsh", "fish", "all"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		target := "all"
 		enable := true
@@ -34,7 +40,13 @@ var motdToggleCmd = &cobra.Command{
 			target = args[0]
 		}
 		if len(args) > 1 {
-			enable = args[1] == "on"
+			switch args[1] {
+			case "on":
+			case "off":
+				enable = false
+			default:
+				return fmt.Errorf("invalid state %q: use on or off", args[1])
+			}
 		}
 
 		return motd.Toggle(target, enable)
@@ -44,9 +56,50 @@ var motdToggleCmd = &cobra.Command{
 var motdShowCmd = &cobra.Command{
 	Use:   "show",
 	Short: "Display the MOTD",
-	Long:  `Display the Message of the Day with system information and a random tip.`,
+	Long: `Display the Message of the Day with system information and a random tip.
+
+--format picks the output:
+  ansi       rendered with the theme's colors
+  plain      rendered without colors, e.g. for login banners
+  markdown   the template output before rendering
+  json       the template data (see 'motd template data'), e.g. for status bars
+
+Without --format, the MOTD is colored on a terminal and plain when the output is redirected
+or NO_COLOR is set.
+
+With --startup, as used by the shell hook, the MOTD follows the "frequency" and "suppress-in"
+settings in motd.json and is hidden when BLUEFIN_CLI_NO_MOTD is set.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return motd.Show()
+		format, _ := cmd.Flags().GetString("format")
+		if startup, _ := cmd.Flags().GetBool("startup"); startup {
+			return motd.ShowAtStartup(format)
+		}
+		return motd.ShowFormat(format)
+	},
+}
+
+var motdFrequencyCmd = &cobra.Command{
+	Use:   "frequency [always|session|daily|DURATION]",
+	Short: "Set how often new shells show the MOTD",
+	Long: `Set how often new shells show the MOTD: in every shell (always), once per login session
+(session), once per day (daily) or at most once per duration, e.g. 4h. Without an argument,
+print the current frequency.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _ := motd.LoadConfig()
+		if len(args) == 0 {
+			fmt.Println(cfg.Frequency)
+			return nil
+		}
+		if err := motd.ValidateFrequency(args[0]); err != nil {
+			return err
+		}
+		cfg.Frequency = args[0]
+		if err := motd.SaveConfig(cfg); err != nil {
+			return err
+		}
+		fmt.Println(tui.SuccessStyle.Render(fmt.Sprintf("✓ MOTD frequency set to: %s", args[0])))
+		return nil
 	},
 }
 
@@ -61,17 +114,28 @@ var motdConfigCmd = &cobra.Command{
 
 		var selectedTheme string
 
+		opts := []huh.Option[string]{
+			huh.NewOption("Slate (default)", "slate"),
+			huh.NewOption("Dark", "dark"),
+			huh.NewOption("Light", "light"),
+			huh.NewOption("Dracula", "dracula"),
+			huh.NewOption("Pink", "pink"),
+		}
+		// Styles from the themes directory
+		cfg, _ := motd.LoadConfig()
+		for _, theme := range motd.Themes(cfg.ThemesDirectory) {
+			switch theme {
+			case "slate", "dark", "light", "dracula", "pink":
+			default:
+				opts = append(opts, huh.NewOption(theme, theme))
+			}
+		}
+
 		form := huh.NewForm(
 			huh.NewGroup(
 				huh.NewSelect[string]().
 					Title("Choose MOTD theme").
-					Options(
-						huh.NewOption("Slate (default)", "slate"),
-						huh.NewOption("Dark", "dark"),
-						huh.NewOption("Light", "light"),
-						huh.NewOption("Dracula", "dracula"),
-						huh.NewOption("Pink", "pink"),
-					).
+					Options(opts...).
 					Value(&selectedTheme),
 			),
 		).WithTheme(tui.AppTheme).WithKeyMap(tui.MenuKeyMap())
@@ -87,11 +151,425 @@ var motdConfigCmd = &cobra.Command{
 	},
 }
 
+var motdUpdatesCmd = &cobra.Command{
+	Use:   "updates",
+	Short: "Show the outdated package count shown in the MOTD",
+	Long: `Show how many Homebrew formulae and casks and flatpaks are outdated. The MOTD shows this
+count when "check-outdated" is on, refreshing it in the background every few hours.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		updates := motd.CachedUpdates()
+		if refresh, _ := cmd.Flags().GetBool("refresh"); refresh {
+			var err error
+			if updates, err = motd.RefreshUpdates(); err != nil {
+				return err
+			}
+		}
+		if updates == nil {
+			fmt.Println(tui.InfoStyle.Render("No update check yet, run 'bluefin-cli motd updates --refresh'"))
+			return nil
+		}
+
+		fmt.Printf("%d formulae, %d casks and %d flatpaks outdated (checked %s)\n",
+			updates.Brew, updates.Cask, updates.Flatpak, updates.Age)
+		if updates.Hint != "" {
+			fmt.Println(tui.InfoStyle.Render("Upgrade with: " + updates.Hint))
+		}
+		return nil
+	},
+}
+
+var motdTipsCmd = &cobra.Command{
+	Use:   "tips",
+	Short: "Manage MOTD tips",
+	Long: `The MOTD shows one tip at a time, rotating through every tip that applies to this machine
+before repeating one. Tips are markdown files with optional front-matter:
+
+  ---
+  category: history
+  requires: atuin
+  shell: bash, zsh
+  os: linux
+  ---
+  Search your shell history with ` + "`atuin`" + ` using Ctrl+R
+
+A tip is only shown when the binaries it requires are installed and the shell and OS match.`,
+}
+
+var motdTipsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List tips and whether they apply here",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _ := motd.LoadConfig()
+		tips, errs := motd.LoadTips(cfg)
+		for _, err := range errs {
+			fmt.Fprintln(os.Stderr, tui.WarningStyle.Render("Warning: "+err.Error()))
+		}
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			return encodeJSON(tips)
+		}
+
+		shown := motd.ShownTips()
+		for _, tip := range tips {
+			state := tui.SuccessStyle.Render("✓")
+			if !tip.Applies() {
+				state = tui.ErrorStyle.Render("✗")
+			} else if shown[tip.ID] {
+				state = tui.InfoStyle.Render("·")
+			}
+			category := tip.Category
+			if category == "" {
+				category = "-"
+			}
+			fmt.Printf("%s %-24s %-12s %s\n", state, tip.ID, category, tip.Text)
+		}
+		fmt.Println(tui.InfoStyle.Render("\n✓ in rotation  · shown in this rotation  ✗ condition not met"))
+		return nil
+	},
+}
+
+var motdTipsAddCmd = &cobra.Command{
+	Use:   "add <id> <text>",
+	Short: "Add a tip",
+	Long:  `Add a tip to ~/.config/bluefin-cli/tips, e.g. bluefin-cli motd tips add k9s "Run ` + "`k9s`" + ` for a cluster dashboard" --requires k9s`,
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tip := motd.Tip{ID: args[0], Text: args[1]}
+		tip.Category, _ = cmd.Flags().GetString("category")
+		tip.Requires, _ = cmd.Flags().GetStringSlice("requires")
+		tip.Shell, _ = cmd.Flags().GetStringSlice("shell")
+		tip.OS, _ = cmd.Flags().GetStringSlice("os")
+
+		path, err := motd.AddTip(tip)
+		if err != nil {
+			return err
+		}
+		fmt.Println(tui.SuccessStyle.Render("✓ Added tip " + path))
+		if !tip.Applies() {
+			fmt.Println(tui.WarningStyle.Render("Its conditions don't hold here, so this machine won't show it"))
+		}
+		return nil
+	},
+}
+
+var motdTipsNextCmd = &cobra.Command{
+	Use:   "next",
+	Short: "Show the next tip of the rotation",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _ := motd.LoadConfig()
+		tip, ok := motd.NextTip(cfg)
+		if !ok {
+			fmt.Println(tui.InfoStyle.Render("No tips apply to this machine"))
+			return nil
+		}
+		rendered, err := motd.Render("💡 **Tip:** "+tip.Text, cfg, motd.TerminalWidth())
+		if err != nil {
+			return err
+		}
+		fmt.Print(rendered)
+		return nil
+	},
+}
+
+var motdFeedsCmd = &cobra.Command{
+	Use:   "feeds",
+	Short: "Manage MOTD announcement feeds",
+	Long: `Show team announcements in the MOTD from JSON, RSS or Atom feeds. Feeds are fetched in the
+background at most hourly, so the MOTD never waits for the network. The MOTD shows up to
+"max-announcements" unread items above the tip until they expire or are dismissed.
+
+A JSON feed looks like:
+  {"items": [{"id": "vpn-0612", "title": "VPN maintenance tonight", "body": "...",
+    "url": "https://...", "published": "2025-06-12T09:00:00Z", "expires": "2025-06-13T06:00:00Z"}]}
+
+Items without an expiry date are hidden two weeks after they were published.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return motdFeedsListCmd.RunE(cmd, args)
+	},
+}
+
+var motdFeedsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List feeds and their current announcements",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _ := motd.LoadConfig()
+		items, dismissed := motd.Announcements(cfg)
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			return encodeJSON(items)
+		}
+		if len(cfg.Feeds) == 0 {
+			fmt.Println(tui.InfoStyle.Render("No feeds configured, add one with 'bluefin-cli motd feeds add <url>'"))
+			return nil
+		}
+
+		for _, url := range cfg.Feeds {
+			fmt.Println(tui.InfoStyle.Render("Feed: " + url))
+		}
+		fmt.Println()
+		if len(items) == 0 {
+			fmt.Println(tui.InfoStyle.Render("No current announcements"))
+			return nil
+		}
+		for _, item := range items {
+			state := tui.SuccessStyle.Render("●")
+			if dismissed[item.ID] {
+				state = tui.InfoStyle.Render("○")
+			}
+			fmt.Printf("%s %s  %s\n", state, item.ID, item.Title)
+		}
+		fmt.Println(tui.InfoStyle.Render("\n● unread  ○ dismissed"))
+		return nil
+	},
+}
+
+var motdFeedsAddCmd = &cobra.Command{
+	Use:   "add <url>",
+	Short: "Add a feed",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _ := motd.LoadConfig()
+		for _, url := range cfg.Feeds {
+			if url == args[0] {
+				return fmt.Errorf("feed %s is already configured", url)
+			}
+		}
+		cfg.Feeds = append(cfg.Feeds, args[0])
+		if err := motd.SaveConfig(cfg); err != nil {
+			return err
+		}
+		fmt.Println(tui.SuccessStyle.Render("✓ Added feed " + args[0]))
+		if err := motd.RefreshFeeds(cfg); err != nil {
+			fmt.Println(tui.WarningStyle.Render(fmt.Sprintf("Warning: %v", err)))
+		}
+		return nil
+	},
+}
+
+var motdFeedsRemoveCmd = &cobra.Command{
+	Use:   "remove <url>",
+	Short: "Remove a feed",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _ := motd.LoadConfig()
+		var feeds []string
+		for _, url := range cfg.Feeds {
+			if url != args[0] {
+				feeds = append(feeds, url)
+			}
+		}
+		if len(feeds) == len(cfg.Feeds) {
+			return fmt.Errorf("feed %s is not configured", args[0])
+		}
+		cfg.Feeds = feeds
+		if err := motd.SaveConfig(cfg); err != nil {
+			return err
+		}
+		fmt.Println(tui.SuccessStyle.Render("✓ Removed feed " + args[0]))
+		return nil
+	},
+}
+
+var motdFeedsRefreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Fetch the feeds now",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _ := motd.LoadConfig()
+		return motd.RefreshFeeds(cfg)
+	},
+}
+
+var motdFeedsDismissCmd = &cobra.Command{
+	Use:   "dismiss [id...]",
+	Short: "Hide announcements from the MOTD",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if all, _ := cmd.Flags().GetBool("all"); all {
+			cfg, _ := motd.LoadConfig()
+			items, _ := motd.Announcements(cfg)
+			args = nil
+			for _, item := range items {
+				args = append(args, item.ID)
+			}
+		} else if len(args) == 0 {
+			return fmt.Errorf("give the IDs of the announcements to dismiss, or --all")
+		}
+		if err := motd.Dismiss(args...); err != nil {
+			return err
+		}
+		if len(args) == 1 {
+			fmt.Println(tui.SuccessStyle.Render("✓ Dismissed 1 announcement"))
+		} else {
+			fmt.Println(tui.SuccessStyle.Render(fmt.Sprintf("✓ Dismissed %d announcements", len(args))))
+		}
+		return nil
+	},
+}
+
+var motdTemplateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Author MOTD templates",
+	Long: `MOTD templates are Go text/template files that produce markdown. Set "template-file" in
+motd.json to a file path or the name of a shipped template (see 'motd template list').
+
+Templates are executed with these fields:
+  .Image.ImageName, .Image.ImageTag, ...  OS image from image-info.json or os-release
+  .Deployment.Booted, .Deployment.Staged   rpm-ostree/bootc images: .Ref, .Version
+                                          (Deployment and Staged may be nil)
+  .Hostname, .User                        Host name and login name
+  .Uptime                                 Time since boot, e.g. "2 days 3 hours"
+  .Announcements                          Unread feed items: .ID, .Title, .Body, .URL,
+                                          .Published, .Expires
+  .Tip                                    The next tip in markdown
+  .System                                 Enabled system info widgets: .Name, .Label, .Value
+  .Tools                                  Shell tools: .Name, .Enabled, .Installed
+  .Updates                                Outdated packages when check-outdated is on:
+                                          .Brew, .Cask, .Flatpak, .Total, .Age, .Hint
+  .Date                                   The current time, e.g. {{.Date.Format "Jan 2"}}
+
+Print the data for this machine as JSON with 'motd template data'.`,
+}
+
+var motdTemplateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the shipped templates",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		for _, name := range motd.ShippedTemplates() {
+			fmt.Println(name)
+		}
+		return nil
+	},
+}
+
+var motdTemplateValidateCmd = &cobra.Command{
+	Use:   "validate <template>",
+	Short: "Check that a template parses and uses only known fields",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		text, err := motd.LoadTemplate(args[0])
+		if err != nil {
+			return err
+		}
+		if err := motd.ValidateTemplate(text); err != nil {
+			return err
+		}
+		fmt.Println(tui.SuccessStyle.Render(fmt.Sprintf("✓ %s is a valid MOTD template", args[0])))
+		return nil
+	},
+}
+
+var motdTemplateRenderCmd = &cobra.Command{
+	Use:   "render [template]",
+	Short: "Render a template, by default the configured one",
+	Long: `Render a template with the data for this machine, or with the data in a JSON file given
+by --data-json (see 'motd template data' for the format).`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _ := motd.LoadConfig()
+		name := cfg.TemplateFile
+		if len(args) > 0 {
+			name = args[0]
+		}
+		text, err := motd.LoadTemplate(name)
+		if err != nil {
+			return err
+		}
+
+		var data motd.TemplateData
+		if path, _ := cmd.Flags().GetString("data-json"); path != "" {
+			if data, err = motd.ReadData(path); err != nil {
+				return err
+			}
+		} else {
+			data = motd.CollectData(cfg)
+		}
+
+		content, err := motd.ExecuteTemplate(text, data)
+		if err != nil {
+			return err
+		}
+		if raw, _ := cmd.Flags().GetBool("raw"); raw {
+			fmt.Print(content)
+			return nil
+		}
+		rendered, err := motd.Render(content, cfg, motd.TerminalWidth())
+		if err != nil {
+			return err
+		}
+		fmt.Print(rendered)
+		return nil
+	},
+}
+
+var motdTemplateDataCmd = &cobra.Command{
+	Use:   "data",
+	Short: "Print the template data for this machine as JSON",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _ := motd.LoadConfig()
+		return encodeJSON(motd.CollectData(cfg))
+	},
+}
+
+var motdDeploymentCmd = &cobra.Command{
+	Use:   "deployment",
+	Short: "Show the booted and staged images shown in the MOTD",
+	Long: `Show the booted and staged images reported by rpm-ostree or bootc. The MOTD reads them from
+a cache that it refreshes in the background every few minutes and after a reboot.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		deployment := motd.CachedDeployment()
+		if refresh, _ := cmd.Flags().GetBool("refresh"); refresh {
+			var err error
+			if deployment, err = motd.RefreshDeployment(); err != nil {
+				return err
+			}
+		}
+		if deployment == nil {
+			fmt.Println(tui.InfoStyle.Render("No image-based deployment found, run 'bluefin-cli motd deployment --refresh' to check now"))
+			return nil
+		}
+
+		fmt.Printf("Booted: %s %s\n", deployment.Booted.Ref, deployment.Booted.Version)
+		if deployment.Staged != nil {
+			fmt.Printf("Staged: %s %s\n", deployment.Staged.Ref, deployment.Staged.Version)
+		}
+		return nil
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(motdCmd)
 	motdCmd.AddCommand(motdToggleCmd)
 	motdCmd.AddCommand(motdShowCmd)
 	motdCmd.AddCommand(motdConfigCmd)
+	motdCmd.AddCommand(motdFrequencyCmd)
+	motdCmd.AddCommand(motdUpdatesCmd)
+	motdCmd.AddCommand(motdDeploymentCmd)
+	motdCmd.AddCommand(motdTipsCmd)
+	motdTipsCmd.AddCommand(motdTipsListCmd)
+	motdTipsCmd.AddCommand(motdTipsAddCmd)
+	motdTipsCmd.AddCommand(motdTipsNextCmd)
+	motdCmd.AddCommand(motdFeedsCmd)
+	motdFeedsCmd.AddCommand(motdFeedsListCmd)
+	motdFeedsCmd.AddCommand(motdFeedsAddCmd)
+	motdFeedsCmd.AddCommand(motdFeedsRemoveCmd)
+	motdFeedsCmd.AddCommand(motdFeedsRefreshCmd)
+	motdFeedsCmd.AddCommand(motdFeedsDismissCmd)
+	motdCmd.AddCommand(motdTemplateCmd)
+	motdTemplateCmd.AddCommand(motdTemplateListCmd)
+	motdTemplateCmd.AddCommand(motdTemplateValidateCmd)
+	motdTemplateCmd.AddCommand(motdTemplateRenderCmd)
+	motdTemplateCmd.AddCommand(motdTemplateDataCmd)
+
+	motdShowCmd.Flags().String("format", "", "Output format: "+strings.Join(motd.Formats, ", "))
+	motdShowCmd.Flags().Bool("startup", false, "Apply the frequency and suppression settings, as the shell hook does")
+	motdUpdatesCmd.Flags().Bool("refresh", false, "Check for outdated packages now")
+	motdDeploymentCmd.Flags().Bool("refresh", false, "Ask rpm-ostree or bootc now")
+	motdTipsListCmd.Flags().Bool("json", false, "Output as JSON")
+	motdTipsAddCmd.Flags().String("category", "", "Category of the tip")
+	motdTipsAddCmd.Flags().StringSlice("requires", nil, "Binaries that must be installed for the tip to be shown")
+	motdTipsAddCmd.Flags().StringSlice("shell", nil, "Shells to show the tip in (bash, zsh, fish)")
+	motdTipsAddCmd.Flags().StringSlice("os", nil, "Operating systems to show the tip on (linux, darwin)")
+	motdFeedsListCmd.Flags().Bool("json", false, "Output as JSON")
+	motdFeedsDismissCmd.Flags().Bool("all", false, "Dismiss every current announcement")
+	motdTemplateRenderCmd.Flags().String("data-json", "", "Render with the data in this JSON file")
+	motdTemplateRenderCmd.Flags().Bool("raw", false, "Print the markdown without rendering it")
 }
 
 func runMotdMenu() error {
@@ -111,7 +589,7 @@ func runMotdMenu() error {
 		if err != nil {
 			cfg = shell.DefaultConfig(currentShell)
 		}
-		isEnabled := cfg.IsEnabled("Motd")
+		isEnabled := cfg.MotdEnabled(currentShell)
 
 		// Build toggle label based on current state
 		toggleLabel := "Enable MOTD"
@@ -119,6 +597,12 @@ func runMotdMenu() error {
 			toggleLabel = "Disable MOTD"
 		}
 
+		motdCfg, _ := motd.LoadConfig()
+		updatesLabel := "Show Pending Updates"
+		if motdCfg.CheckOutdated {
+			updatesLabel = "Hide Pending Updates"
+		}
+
 		var action string
 		if err := huh.NewForm(
 			huh.NewGroup(
@@ -126,6 +610,9 @@ func runMotdMenu() error {
 					Title("MOTD – What do you want to do?").
 					Options(
 						huh.NewOption(toggleLabel, "toggle_motd"),
+						huh.NewOption(updatesLabel, "toggle_updates"),
+						huh.NewOption("System Info Widgets", "widgets"),
+						huh.NewOption("Frequency ("+motdCfg.Frequency+")", "frequency"),
 						huh.NewOption("Show MOTD", "show"),
 						huh.NewOption("Exit to Shell Menu", "exit"),
 					).
@@ -137,15 +624,65 @@ func runMotdMenu() error {
 
 		switch action {
 		case "toggle_motd":
-			cfg.SetEnabled("Motd", !isEnabled)
-			if err := shell.SaveConfig(cfg); err != nil {
+			if err := motd.Toggle("all", !isEnabled); err != nil {
 				return fmt.Errorf("failed to save config: %w", err)
 			}
-			if !isEnabled {
-				fmt.Println(tui.SuccessStyle.Render("✓ MOTD enabled"))
+			tui.Pause()
+		case "toggle_updates":
+			motdCfg.CheckOutdated = !motdCfg.CheckOutdated
+			if err := motd.SaveConfig(motdCfg); err != nil {
+				return fmt.Errorf("failed to save config: %w", err)
+			}
+			if motdCfg.CheckOutdated {
+				fmt.Println(tui.SuccessStyle.Render("✓ The MOTD will show pending updates"))
 			} else {
-				fmt.Println(tui.SuccessStyle.Render("✓ MOTD disabled"))
+				fmt.Println(tui.SuccessStyle.Render("✓ The MOTD will no longer show pending updates"))
+			}
+			tui.Pause()
+		case "widgets":
+			selected := motdCfg.Widgets
+			var opts []huh.Option[string]
+			for _, name := range motd.WidgetNames() {
+				opts = append(opts, huh.NewOption(name, name))
+			}
+			if err := huh.NewForm(
+				huh.NewGroup(
+					huh.NewMultiSelect[string]().
+						Title("Show system information above the MOTD").
+						Options(opts...).
+						Value(&selected),
+				),
+			).WithTheme(tui.AppTheme).WithKeyMap(tui.MenuKeyMap()).Run(); err != nil {
+				continue
+			}
+			motdCfg.Widgets = selected
+			if err := motd.SaveConfig(motdCfg); err != nil {
+				return fmt.Errorf("failed to save config: %w", err)
+			}
+			fmt.Println(tui.SuccessStyle.Render("✓ System info widgets saved"))
+			tui.Pause()
+		case "frequency":
+			frequency := motdCfg.Frequency
+			if err := huh.NewForm(
+				huh.NewGroup(
+					huh.NewSelect[string]().
+						Title("How often should new shells show the MOTD?").
+						Options(
+							huh.NewOption("In every shell", motd.FrequencyAlways),
+							huh.NewOption("Once per login session", motd.FrequencySession),
+							huh.NewOption("Once every 4 hours", "4h"),
+							huh.NewOption("Once per day", motd.FrequencyDaily),
+						).
+						Value(&frequency),
+				),
+			).WithTheme(tui.AppTheme).WithKeyMap(tui.MenuKeyMap()).Run(); err != nil {
+				continue
+			}
+			motdCfg.Frequency = frequency
+			if err := motd.SaveConfig(motdCfg); err != nil {
+				return fmt.Errorf("failed to save config: %w", err)
 			}
+			fmt.Println(tui.SuccessStyle.Render(fmt.Sprintf("✓ MOTD frequency set to: %s", frequency)))
 			tui.Pause()
 		case "show":
 			if err := motd.Show(); err != nil {
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/hanthor/bluefin-cli/internal/profile"
+	"github.com/hanthor/bluefin-cli/internal/shell"
+	"github.com/hanthor/bluefin-cli/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Switch between named sets of shell, MOTD and Starship settings",
+	Long: `Profiles store the shell tool settings, MOTD config and Starship config together, e.g. a
+minimal one for screen-sharing demos and a full one for daily work.
+
+  bluefin-cli profile create demo
+  bluefin-cli profile use work`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runProfileMenu()
+	},
+}
+
+var profileCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Save the current settings as a profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		force, _ := cmd.Flags().GetBool("force")
+		if _, err := profile.Create(args[0], currentShellName(), force); err != nil {
+			return err
+		}
+		fmt.Println(tui.SuccessStyle.Render(fmt.Sprintf("✓ Saved current settings as profile %s", args[0])))
+		return nil
+	},
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Switch to a profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return useProfile(args[0])
+	},
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List profiles",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profiles, err := profile.List()
+		if err != nil {
+			return err
+		}
+		if len(profiles) == 0 {
+			fmt.Println(tui.InfoStyle.Render("No profiles yet. Create one with 'bluefin-cli profile create <name>'"))
+			return nil
+		}
+
+		active := profile.Active()
+		for _, p := range profiles {
+			marker := "  "
+			if p.Name == active {
+				marker = tui.SuccessStyle.Render("* ")
+			}
+			fmt.Printf("%s%-20s %s\n", marker, p.Name, profileDescription(&p))
+		}
+		return nil
+	},
+}
+
+var profileDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := profile.Delete(args[0]); err != nil {
+			return err
+		}
+		fmt.Println(tui.SuccessStyle.Render(fmt.Sprintf("✓ Deleted profile %s", args[0])))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileCreateCmd)
+	profileCmd.AddCommand(profileUseCmd)
+	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileDeleteCmd)
+
+	profileCreateCmd.Flags().BoolP("force", "f", false, "Overwrite an existing profile")
+}
+
+// profileDescription summarizes a profile as its MOTD theme and Starship preset
+func profileDescription(p *profile.Profile) string {
+	parts := []string{"motd: off"}
+	if p.Shell.IsEnabled("motd") {
+		parts[0] = "motd: " + p.Motd.DefaultTheme
+	}
+	if p.StarshipPreset != "" {
+		parts = append(parts, "starship: "+p.StarshipPreset)
+	} else if p.StarshipConfig != "" {
+		parts = append(parts, "starship: custom")
+	}
+	return strings.Join(parts, ", ")
+}
+
+func useProfile(name string) error {
+	restart, err := profile.Use(name, currentShellName())
+	if err != nil {
+		return err
+	}
+
+	// Install the tools the profile enables, like the shell menu does
+	if p, err := profile.Load(name); err == nil {
+		shell.InstallTools(&p.Shell)
+	}
+
+	fmt.Println(tui.SuccessStyle.Render(fmt.Sprintf("✓ Switched to profile %s", name)))
+	if len(restart) > 0 {
+		fmt.Println(tui.InfoStyle.Render(fmt.Sprintf("Restart these shells to apply it: %s", strings.Join(restart, ", "))))
+	}
+	return nil
+}
+
+func runProfileMenu() error {
+	for {
+		tui.ClearScreen()
+		tui.RenderHeader("Bluefin CLI", "Main Menu > Profiles")
+
+		profiles, err := profile.List()
+		if err != nil {
+			return err
+		}
+
+		active := profile.Active()
+		var opts []huh.Option[string]
+		for _, p := range profiles {
+			label := fmt.Sprintf("%s (%s)", p.Name, profileDescription(&p))
+			if p.Name == active {
+				label += " ✓"
+			}
+			opts = append(opts, huh.NewOption(label, p.Name))
+		}
+		opts = append(opts,
+			huh.NewOption("➕ Save current settings as a profile", "__create"),
+			huh.NewOption("Exit to Main Menu", "__exit"),
+		)
+
+		var choice string
+		if err := huh.NewForm(
+			huh.NewGroup(
+				huh.NewSelect[string]().
+					Title("Switch profile").
+					Options(opts...).
+					Value(&choice),
+			),
+		).WithTheme(tui.AppTheme).WithKeyMap(tui.MenuKeyMap()).Run(); err != nil {
+			return nil
+		}
+
+		switch choice {
+		case "__exit":
+			return nil
+		case "__create":
+			var name string
+			if err := huh.NewInput().
+				Title("Profile name").
+				Value(&name).
+				WithTheme(tui.AppTheme).
+				Run(); err != nil || strings.TrimSpace(name) == "" {
+				continue
+			}
+			if _, err := profile.Create(strings.TrimSpace(name), currentShellName(), false); err != nil {
+				fmt.Println(tui.ErrorStyle.Render(err.Error()))
+			} else {
+				fmt.Println(tui.SuccessStyle.Render(fmt.Sprintf("✓ Saved current settings as profile %s", name)))
+			}
+		default:
+			if err := useProfile(choice); err != nil {
+				fmt.Println(tui.ErrorStyle.Render(err.Error()))
+			}
+		}
+		tui.Pause()
+	}
+}
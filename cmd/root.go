@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/hanthor/bluefin-cli/internal/httpclient"
 	"github.com/spf13/cobra"
 )
 
@@ -33,4 +34,5 @@ func Execute() error {
 
 func init() {
 	rootCmd.SetVersionTemplate(fmt.Sprintf("bluefin-cli version %s\n", version))
+	httpclient.SetVersion(version)
 }
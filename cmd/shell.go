@@ -42,12 +42,41 @@ The Shell Experience provides:
 var shellConfigCmd = &cobra.Command{
 	Use:   "config",
 	Short: "Configure individual shell experience tools",
-	Long:  `Enable or disable specific shell experience components interactively.`,
+	Long: `Enable or disable specific shell experience components interactively.
+
+With --prune, uninstall the tools bluefin-cli installed that are no longer enabled.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if prune, _ := cmd.Flags().GetBool("prune"); prune {
+			return pruneShellTools(cmd)
+		}
 		return configureShellTools()
 	},
 }
 
+func pruneShellTools(cmd *cobra.Command) error {
+	cfg, err := shell.LoadConfig(currentShellName())
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	entries, err := shell.PrunableTools(cfg)
+	if err != nil {
+		return err
+	}
+
+	yes, _ := cmd.Flags().GetBool("yes")
+	return removeRecorded(entries, "disabled shell tools", yes)
+}
+
+// currentShellName returns the name of $SHELL, falling back to bash
+func currentShellName() string {
+	name := filepath.Base(os.Getenv("SHELL"))
+	if name == "" || name == "." {
+		return "bash"
+	}
+	return name
+}
+
 func runShellMenu() error {
 	for {
 		tui.ClearScreen()
@@ -242,4 +271,7 @@ func init() {
 
 	rootCmd.AddCommand(shellCmd)
 	shellCmd.AddCommand(shellConfigCmd)
+
+	shellConfigCmd.Flags().Bool("prune", false, "Uninstall tools bluefin-cli installed that are now disabled")
+	shellConfigCmd.Flags().BoolP("yes", "y", false, "With --prune, remove without asking for confirmation")
 }
@@ -0,0 +1,349 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/huh"
+	"github.com/dustin/go-humanize"
+	"github.com/hanthor/bluefin-cli/internal/install"
+	"github.com/hanthor/bluefin-cli/internal/ledger"
+	"github.com/hanthor/bluefin-cli/internal/tui"
+	"github.com/hanthor/bluefin-cli/internal/wallpaper"
+	"github.com/spf13/cobra"
+)
+
+var wallpapersCmd = &cobra.Command{
+	Use:   "wallpapers",
+	Short: "Install, apply and rotate wallpapers",
+	Long:  `Manage wallpaper collections installed from Homebrew wallpaper casks.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWallpapersMenu()
+	},
+}
+
+var wallpapersListInstalledCmd = &cobra.Command{
+	Use:   "list-installed",
+	Short: "List installed wallpaper collections",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		collections, err := wallpaper.ListInstalled()
+		if err != nil {
+			return err
+		}
+		if len(collections) == 0 {
+			fmt.Println(tui.InfoStyle.Render("No wallpaper collections installed. Try 'bluefin-cli install wallpapers'."))
+			return nil
+		}
+		for _, c := range collections {
+			fmt.Printf("  %s %s\n", tui.SuccessStyle.Render(c.Name), fmt.Sprintf("(%d images) %s", len(c.Images), c.Path))
+		}
+		return nil
+	},
+}
+
+var wallpapersApplyCmd = &cobra.Command{
+	Use:   "apply <file|collection>",
+	Short: "Set the desktop wallpaper",
+	Long: `Set the desktop wallpaper to an image file, or to the next image of an installed collection.
+
+GNOME is configured through gsettings (org.gnome.desktop.background), KDE Plasma through
+plasma-apply-wallpaperimage, and macOS through System Events.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return wallpaper.Apply(args[0])
+	},
+}
+
+var wallpapersRotateCmd = &cobra.Command{
+	Use:   "rotate <collection>",
+	Short: "Rotate through a wallpaper collection on a timer",
+	Long: `Install a systemd user timer that applies the next image of a collection every interval.
+
+  bluefin-cli wallpapers rotate bluefin --interval 30m
+  bluefin-cli wallpapers rotate --stop`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if stop, _ := cmd.Flags().GetBool("stop"); stop {
+			return wallpaper.DisableRotation()
+		}
+		if len(args) == 0 {
+			return fmt.Errorf("a collection is required (see 'bluefin-cli wallpapers list-installed')")
+		}
+		interval, _ := cmd.Flags().GetDuration("interval")
+		return wallpaper.EnableRotation(args[0], interval)
+	},
+}
+
+var wallpapersInstallCmd = &cobra.Command{
+	Use:   "install [cask...]",
+	Short: "Install wallpaper casks from the configured taps",
+	Args:  cobra.ArbitraryArgs,
+	RunE:  installWallpapersCmd.RunE,
+}
+
+var wallpapersRemoveCmd = &cobra.Command{
+	Use:   "remove [cask...]",
+	Short: "Uninstall wallpaper casks installed by bluefin-cli",
+	Long:  `Uninstall the given wallpaper casks, or every wallpaper cask bluefin-cli installed when none are given.`,
+	Args:  cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		installed, err := ledger.Installed()
+		if err != nil {
+			return err
+		}
+		requested := make(map[string]bool)
+		for _, a := range args {
+			requested[a[strings.LastIndex(a, "/")+1:]] = true
+		}
+		entries := ledger.Filter(installed, func(e ledger.Entry) bool {
+			return e.Bundle == ledger.WallpapersBundle && (len(args) == 0 || requested[e.Package])
+		})
+
+		yes, _ := cmd.Flags().GetBool("yes")
+		return removeRecorded(entries, "wallpapers", yes)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(wallpapersCmd)
+	wallpapersCmd.AddCommand(wallpapersListInstalledCmd)
+	wallpapersCmd.AddCommand(wallpapersApplyCmd)
+	wallpapersCmd.AddCommand(wallpapersRotateCmd)
+	wallpapersCmd.AddCommand(wallpapersInstallCmd)
+	wallpapersCmd.AddCommand(wallpapersRemoveCmd)
+
+	wallpapersRotateCmd.Flags().Duration("interval", time.Hour, "How often to change the wallpaper")
+	wallpapersRotateCmd.Flags().Bool("stop", false, "Stop rotating and remove the timer")
+	wallpapersRemoveCmd.Flags().BoolP("yes", "y", false, "Remove without asking for confirmation")
+}
+
+func runWallpapersMenu() error {
+	for {
+		tui.ClearScreen()
+		tui.RenderHeader("Bluefin CLI", "Main Menu > Wallpapers")
+
+		var action string
+		if err := huh.NewForm(
+			huh.NewGroup(
+				huh.NewSelect[string]().
+					Title("Wallpapers – What do you want to do?").
+					Options(
+						huh.NewOption("Install wallpaper collections ❯", "install"),
+						huh.NewOption("Apply an installed wallpaper ❯", "apply"),
+						huh.NewOption("Rotate wallpapers ❯", "rotate"),
+						huh.NewOption("Exit to Main Menu", "exit"),
+					).
+					Value(&action),
+			),
+		).WithTheme(tui.AppTheme).WithKeyMap(tui.MenuKeyMap()).Run(); err != nil {
+			return nil
+		}
+
+		switch action {
+		case "install":
+			if err := runWallpaperInstallMenu(); err != nil {
+				return err
+			}
+			tui.Pause()
+		case "apply":
+			if err := runWallpaperApplyMenu(); err != nil {
+				return err
+			}
+		case "rotate":
+			if err := runWallpaperRotateMenu(); err != nil {
+				return err
+			}
+		case "exit":
+			return nil
+		}
+	}
+}
+
+// selectCollection asks for an installed collection; it returns false when there is none or the user backs out
+func selectCollection(title string) (wallpaper.Collection, bool) {
+	collections, err := wallpaper.ListInstalled()
+	if err != nil || len(collections) == 0 {
+		fmt.Println(tui.InfoStyle.Render("No wallpaper collections installed yet. Install some first."))
+		tui.Pause()
+		return wallpaper.Collection{}, false
+	}
+
+	opts := make([]huh.Option[int], 0, len(collections))
+	for i, c := range collections {
+		opts = append(opts, huh.NewOption(fmt.Sprintf("%s (%d images)", c.Name, len(c.Images)), i))
+	}
+
+	var selected int
+	if err := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[int]().
+				Title(title).
+				Options(opts...).
+				Value(&selected),
+		),
+	).WithTheme(tui.AppTheme).WithKeyMap(tui.MenuKeyMap()).Run(); err != nil {
+		return wallpaper.Collection{}, false
+	}
+	return collections[selected], true
+}
+
+func runWallpaperApplyMenu() error {
+	tui.ClearScreen()
+	tui.RenderHeader("Bluefin CLI", "Main Menu > Wallpapers > Apply")
+
+	collection, ok := selectCollection("Choose a collection")
+	if !ok {
+		return nil
+	}
+
+	opts := make([]huh.Option[string], 0, len(collection.Images))
+	for _, img := range collection.Images {
+		rel, err := filepath.Rel(collection.Path, img)
+		if err != nil {
+			rel = filepath.Base(img)
+		}
+		opts = append(opts, huh.NewOption(rel, img))
+	}
+
+	var image string
+	if err := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Choose a wallpaper").
+				Options(opts...).
+				Height(15).
+				Value(&image),
+		),
+	).WithTheme(tui.AppTheme).WithKeyMap(tui.MenuKeyMap()).Run(); err != nil {
+		return nil
+	}
+
+	tui.ClearScreen()
+	tui.RenderHeader("Bluefin CLI", "Main Menu > Wallpapers > Apply")
+	if tui.DetectImageProtocol() != tui.ProtocolNone {
+		if err := tui.PreviewImage(os.Stdout, image, 60, 18); err != nil {
+			fmt.Println(tui.WarningStyle.Render(fmt.Sprintf("Preview unavailable: %v", err)))
+		}
+	}
+
+	apply := true
+	if err := huh.NewConfirm().
+		Title(fmt.Sprintf("Apply %s?", filepath.Base(image))).
+		Value(&apply).
+		WithTheme(tui.AppTheme).
+		Run(); err != nil || !apply {
+		return nil
+	}
+
+	if err := wallpaper.Apply(image); err != nil {
+		fmt.Println(tui.ErrorStyle.Render(err.Error()))
+	}
+	tui.Pause()
+	return nil
+}
+
+func runWallpaperRotateMenu() error {
+	tui.ClearScreen()
+	tui.RenderHeader("Bluefin CLI", "Main Menu > Wallpapers > Rotate")
+
+	collection, ok := selectCollection("Choose a collection to rotate through")
+	if !ok {
+		return nil
+	}
+
+	var interval time.Duration
+	if err := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[time.Duration]().
+				Title("How often should the wallpaper change?").
+				Options(
+					huh.NewOption("Every 15 minutes", 15*time.Minute),
+					huh.NewOption("Every 30 minutes", 30*time.Minute),
+					huh.NewOption("Every hour", time.Hour),
+					huh.NewOption("Every day", 24*time.Hour),
+					huh.NewOption("Stop rotating", time.Duration(0)),
+				).
+				Value(&interval),
+		),
+	).WithTheme(tui.AppTheme).WithKeyMap(tui.MenuKeyMap()).Run(); err != nil {
+		return nil
+	}
+
+	var err error
+	if interval == 0 {
+		err = wallpaper.DisableRotation()
+	} else {
+		err = wallpaper.EnableRotation(collection.Name, interval)
+	}
+	if err != nil {
+		fmt.Println(tui.ErrorStyle.Render(err.Error()))
+	}
+	tui.Pause()
+	return nil
+}
+
+// runWallpaperInstallMenu lets the user pick wallpaper casks to install
+func runWallpaperInstallMenu() error {
+	tui.ClearScreen()
+	tui.RenderHeader("Bluefin CLI", "Main Menu > Wallpapers > Install")
+	casks, err := install.GetWallpaperCasks()
+	if err != nil {
+		return fmt.Errorf("failed to discover wallpaper casks: %w", err)
+	}
+	if len(casks) == 0 {
+		return fmt.Errorf("no wallpaper casks found in %s", strings.Join(install.WallpaperTaps(), ", "))
+	}
+
+	opts := make([]huh.Option[string], 0, len(casks))
+	for _, c := range casks {
+		opts = append(opts, huh.NewOption(wallpaperCaskLabel(c), c.Token))
+	}
+
+	var selected []string
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewMultiSelect[string]().
+				Title("Select wallpapers to install (space to select, enter to confirm)").
+				Description("✓ marks collections that are already installed").
+				Options(opts...).
+				Value(&selected),
+		),
+	).WithTheme(tui.AppTheme).WithKeyMap(tui.MenuKeyMap())
+	if err := form.Run(); err != nil {
+		if err == huh.ErrUserAborted {
+			return nil
+		}
+		return fmt.Errorf("form error: %w", err)
+	}
+	if len(selected) == 0 {
+		return fmt.Errorf("no wallpapers selected")
+	}
+	return install.InstallWallpaperCasks(selected)
+}
+
+// wallpaperCaskLabel describes a cask on one line: token, description, version and installed state
+func wallpaperCaskLabel(c install.CaskInfo) string {
+	label := c.Token
+	if c.Desc != "" {
+		label += " – " + c.Desc
+	}
+
+	var details []string
+	if c.Version != "" {
+		details = append(details, c.Version)
+	}
+	if c.Installed {
+		installed := "✓ installed"
+		if c.Size > 0 {
+			installed += ", " + humanize.Bytes(uint64(c.Size))
+		}
+		details = append(details, installed)
+	}
+	if len(details) > 0 {
+		label += " (" + strings.Join(details, ", ") + ")"
+	}
+	return label
+}
@@ -0,0 +1,188 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hanthor/bluefin-cli/internal/env"
+	"github.com/hanthor/bluefin-cli/internal/policy"
+)
+
+// PackageManagersEnv overrides the package manager preference order for a single run,
+// e.g. BLUEFIN_CLI_PACKAGE_MANAGERS=dnf,brew
+const PackageManagersEnv = "BLUEFIN_CLI_PACKAGE_MANAGERS"
+
+// BundleBaseURLEnv overrides the URL bundle Brewfiles are downloaded from
+const BundleBaseURLEnv = "BLUEFIN_CLI_BUNDLE_BASE_URL"
+
+// CABundleEnv adds a PEM file of trusted certificate authorities for a single run
+const CABundleEnv = "BLUEFIN_CLI_CA_BUNDLE"
+
+// Settings holds general bluefin-cli preferences stored in config.json
+type Settings struct {
+	// PackageManagers is the order in which package backends are tried when installing tools
+	PackageManagers []string `json:"package-managers,omitempty"`
+	// FlatpakScope is the installation ("user" or "system") flatpak apps are installed into
+	FlatpakScope string `json:"flatpak-scope,omitempty"`
+	// WallpaperTaps are the Homebrew taps searched for wallpaper casks
+	WallpaperTaps []string `json:"wallpaper-taps,omitempty"`
+	// BundleBaseURL replaces the URL bundle Brewfiles are downloaded from
+	BundleBaseURL string `json:"bundle-base-url,omitempty"`
+	// BundleManifest is the URL of a sha256sum-style manifest that downloaded Brewfiles must match
+	BundleManifest string `json:"bundle-manifest,omitempty"`
+	// HTTPTimeout bounds each HTTP request, as a Go duration such as "30s"
+	HTTPTimeout string `json:"http-timeout,omitempty"`
+	// HTTPRetries is how many times failed downloads are retried, with exponential backoff
+	HTTPRetries int `json:"http-retries"`
+	// CABundle is a PEM file of extra certificate authorities to trust, e.g. a corporate proxy's
+	CABundle string `json:"ca-bundle,omitempty"`
+}
+
+// DefaultSettings returns the built-in settings
+func DefaultSettings() Settings {
+	return Settings{
+		PackageManagers: []string{"brew", "dnf", "apt", "pacman", "nix"},
+		FlatpakScope:    "user",
+		WallpaperTaps:   []string{"ublue-os/tap"},
+		HTTPTimeout:     "30s",
+		HTTPRetries:     3,
+	}
+}
+
+// Load reads config.json from the config directory, filling unset values with the policy
+// settings and then the defaults. Locked policy keys override both config.json and the
+// environment.
+func Load() (Settings, error) {
+	settings := DefaultSettings()
+	// A broken policy file is reported by status rather than failing every command
+	pol, _ := policy.Load()
+
+	data, err := readUserConfig()
+	if err != nil {
+		return settings, err
+	}
+
+	// Keys missing from the file keep their default values
+	merged, err := pol.Merge("config", data)
+	if err == nil {
+		err = json.Unmarshal(merged, &settings)
+	}
+	if err != nil {
+		return DefaultSettings(), fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	for _, override := range envOverrides {
+		if value := os.Getenv(override.env); value != "" && !pol.IsLocked("config."+override.key) {
+			override.apply(&settings, value)
+		}
+	}
+
+	return settings, nil
+}
+
+// envOverride is a setting that can be overridden for a single run with an environment variable
+type envOverride struct {
+	key, env string
+	apply    func(*Settings, string)
+}
+
+var envOverrides = []envOverride{
+	{"package-managers", PackageManagersEnv, func(s *Settings, v string) { s.PackageManagers = splitList(v) }},
+	{"bundle-base-url", BundleBaseURLEnv, func(s *Settings, v string) { s.BundleBaseURL = v }},
+	{"ca-bundle", CABundleEnv, func(s *Settings, v string) { s.CABundle = v }},
+}
+
+func readUserConfig() ([]byte, error) {
+	path, err := getConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	return data, nil
+}
+
+// Effective returns every setting with its effective value and where it came from
+func Effective() ([]policy.Value, error) {
+	pol, _ := policy.Load()
+
+	defaults, err := json.Marshal(DefaultSettings())
+	if err != nil {
+		return nil, err
+	}
+	data, err := readUserConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := pol.Resolve("config", defaults, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	settings, _ := Load()
+	current, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(current, &fields); err != nil {
+		return nil, err
+	}
+	for i, v := range values {
+		for _, override := range envOverrides {
+			if v.Key == "config."+override.key && os.Getenv(override.env) != "" && !pol.IsLocked(v.Key) {
+				values[i].Value, values[i].Source = fields[override.key], policy.SourceEnv
+			}
+		}
+	}
+	return values, nil
+}
+
+// Save writes the settings to config.json in the config directory
+func Save(settings Settings) error {
+	dir, err := env.EnsureConfigDir()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	pol, _ := policy.Load()
+	if err := pol.CheckLocked("config", data); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	return nil
+}
+
+func getConfigPath() (string, error) {
+	dir, err := env.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
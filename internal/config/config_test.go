@@ -0,0 +1,134 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hanthor/bluefin-cli/internal/policy"
+)
+
+func TestLoadDefaults(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	t.Setenv("HOMEBREW_PREFIX", "")
+	t.Setenv(PackageManagersEnv, "")
+
+	settings, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(settings, DefaultSettings()) {
+		t.Errorf("Expected default settings, got %+v", settings)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	t.Setenv("HOMEBREW_PREFIX", "")
+	t.Setenv(PackageManagersEnv, "")
+
+	want := Settings{PackageManagers: []string{"nix", "brew"}}
+	if err := Save(want); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpHome, ".config", "bluefin-cli", "config.json")); err != nil {
+		t.Fatalf("Expected config.json to be written: %v", err)
+	}
+
+	got, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if !reflect.DeepEqual(got.PackageManagers, want.PackageManagers) {
+		t.Errorf("Expected %v, got %v", want.PackageManagers, got.PackageManagers)
+	}
+}
+
+func TestPackageManagersEnvOverride(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	t.Setenv("HOMEBREW_PREFIX", "")
+	t.Setenv(PackageManagersEnv, " dnf, ,brew ")
+
+	settings, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	want := []string{"dnf", "brew"}
+	if !reflect.DeepEqual(settings.PackageManagers, want) {
+		t.Errorf("Expected %v, got %v", want, settings.PackageManagers)
+	}
+}
+
+func TestPolicyLayering(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	t.Setenv("HOMEBREW_PREFIX", "")
+	t.Setenv(PackageManagersEnv, "")
+	t.Setenv(BundleBaseURLEnv, "https://env.example.com")
+
+	setSystemPolicyDir(t)
+	policyPath := filepath.Join(policy.SystemDir, "policy.json")
+	if err := os.WriteFile(policyPath, []byte(`{
+		"settings": {"config.flatpak-scope": "system", "config.bundle-base-url": "https://mirror.example.com", "config.http-retries": 5},
+		"locked": ["config.bundle-base-url"]
+	}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Users can override policy defaults, but not locked keys
+	if err := Save(Settings{HTTPRetries: 1, BundleBaseURL: "https://user.example.com"}); err == nil {
+		t.Error("Expected saving a locked key to fail")
+	}
+	if err := Save(Settings{HTTPRetries: 1}); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	settings, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if settings.BundleBaseURL != "https://mirror.example.com" {
+		t.Errorf("Expected the locked mirror to beat the environment, got %s", settings.BundleBaseURL)
+	}
+	if settings.FlatpakScope != "system" {
+		t.Errorf("Expected the policy flatpak scope, got %s", settings.FlatpakScope)
+	}
+	if settings.HTTPRetries != 1 {
+		t.Errorf("Expected the user's retries to beat the policy default, got %d", settings.HTTPRetries)
+	}
+
+	values, err := Effective()
+	if err != nil {
+		t.Fatalf("Effective() failed: %v", err)
+	}
+	sources := make(map[string]string)
+	for _, v := range values {
+		sources[v.Key] = v.Source
+	}
+	want := map[string]string{
+		"config.bundle-base-url": policy.SourceLocked,
+		"config.flatpak-scope":   policy.SourcePolicy,
+		"config.http-retries":    policy.SourceUser,
+		"config.http-timeout":    policy.SourceDefault,
+	}
+	for key, source := range want {
+		if sources[key] != source {
+			t.Errorf("%s: source = %s, want %s", key, sources[key], source)
+		}
+	}
+}
+
+// setSystemPolicyDir points the machine-wide policy at an empty temporary directory
+func setSystemPolicyDir(t *testing.T) {
+	t.Helper()
+	origSystemDir := policy.SystemDir
+	policy.SystemDir = t.TempDir()
+	t.Cleanup(func() { policy.SystemDir = origSystemDir })
+}
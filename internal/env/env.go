@@ -44,3 +44,84 @@ func EnsureConfigDir() (string, error) {
 
 	return path, nil
 }
+
+// GetStateDir returns the directory for runtime state such as timestamps and history.
+// It follows the XDG base directory spec: $XDG_STATE_HOME/bluefin-cli, falling back
+// to ~/.local/state/bluefin-cli.
+func GetStateDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "bluefin-cli"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state", "bluefin-cli"), nil
+}
+
+// EnsureStateDir creates the state directory if it doesn't exist
+func EnsureStateDir() (string, error) {
+	path, err := GetStateDir()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return "", fmt.Errorf("failed to create state directory at %s: %w", path, err)
+	}
+
+	return path, nil
+}
+
+// GetCacheDir returns the directory for downloaded files that can be fetched again.
+// It follows the XDG base directory spec: $XDG_CACHE_HOME/bluefin-cli, falling back
+// to ~/.cache/bluefin-cli.
+func GetCacheDir() (string, error) {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "bluefin-cli"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".cache", "bluefin-cli"), nil
+}
+
+// EnsureCacheDir creates the cache directory if it doesn't exist
+func EnsureCacheDir() (string, error) {
+	path, err := GetCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return "", fmt.Errorf("failed to create cache directory at %s: %w", path, err)
+	}
+
+	return path, nil
+}
+
+// WriteFileAtomic writes data to a temporary file next to path and renames it into place,
+// so readers see either the old or the new content, never a partial write
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
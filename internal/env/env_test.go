@@ -55,3 +55,88 @@ func TestGetConfigDir(t *testing.T) {
 		t.Errorf("Expected Local config %s (fallback), got %s", homeConfig, dir)
 	}
 }
+
+func TestGetStateDir(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+
+	t.Setenv("XDG_STATE_HOME", "")
+	dir, err := GetStateDir()
+	if err != nil {
+		t.Fatalf("GetStateDir failed: %v", err)
+	}
+	if want := filepath.Join(tmpHome, ".local", "state", "bluefin-cli"); dir != want {
+		t.Errorf("Expected %s, got %s", want, dir)
+	}
+
+	xdgState := filepath.Join(tmpHome, "state")
+	t.Setenv("XDG_STATE_HOME", xdgState)
+	dir, err = EnsureStateDir()
+	if err != nil {
+		t.Fatalf("EnsureStateDir failed: %v", err)
+	}
+	if want := filepath.Join(xdgState, "bluefin-cli"); dir != want {
+		t.Errorf("Expected %s, got %s", want, dir)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("Expected state dir to be created: %v", err)
+	}
+}
+
+func TestGetCacheDir(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+
+	t.Setenv("XDG_CACHE_HOME", "")
+	dir, err := GetCacheDir()
+	if err != nil {
+		t.Fatalf("GetCacheDir failed: %v", err)
+	}
+	if want := filepath.Join(tmpHome, ".cache", "bluefin-cli"); dir != want {
+		t.Errorf("Expected %s, got %s", want, dir)
+	}
+
+	xdgCache := filepath.Join(tmpHome, "cache")
+	t.Setenv("XDG_CACHE_HOME", xdgCache)
+	dir, err = EnsureCacheDir()
+	if err != nil {
+		t.Fatalf("EnsureCacheDir failed: %v", err)
+	}
+	if want := filepath.Join(xdgCache, "bluefin-cli"); dir != want {
+		t.Errorf("Expected %s, got %s", want, dir)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("Expected cache dir to be created: %v", err)
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "shell.json")
+
+	for _, content := range []string{"first", "second"} {
+		if err := WriteFileAtomic(path, []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFileAtomic failed: %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != content {
+			t.Errorf("Expected %q, got %q", content, data)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("Expected mode 0644, got %v", info.Mode().Perm())
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("Expected no leftover temporary files, got %d entries", len(entries))
+	}
+}
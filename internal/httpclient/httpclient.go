@@ -0,0 +1,280 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hanthor/bluefin-cli/internal/config"
+	"github.com/hanthor/bluefin-cli/internal/env"
+)
+
+// Options configure the shared client
+type Options struct {
+	Timeout  time.Duration
+	Retries  int
+	CABundle string // PEM file of extra trusted certificate authorities
+}
+
+var (
+	version = "dev"
+
+	// baseDelay is the wait before the first retry; it doubles on every further attempt
+	baseDelay = 500 * time.Millisecond
+
+	defaultMu      sync.Mutex
+	defaultOptions Options
+	defaultClient  *http.Client
+)
+
+// SetVersion sets the version reported in the User-Agent header
+func SetVersion(v string) {
+	version = v
+}
+
+// UserAgent returns the User-Agent sent with every request, e.g. "bluefin-cli/0.0.3"
+func UserAgent() string {
+	return "bluefin-cli/" + version
+}
+
+// LoadOptions reads the HTTP settings from config.json
+func LoadOptions() Options {
+	settings, _ := config.Load()
+	opts := Options{Retries: settings.HTTPRetries, CABundle: settings.CABundle}
+	if d, err := time.ParseDuration(settings.HTTPTimeout); err == nil {
+		opts.Timeout = d
+	}
+	return opts
+}
+
+// Default returns the client configured from config.json. It is rebuilt only when the
+// settings change, so connections are reused across calls.
+func Default() *http.Client {
+	opts := LoadOptions()
+
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultClient != nil && opts == defaultOptions {
+		return defaultClient
+	}
+
+	client, err := New(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v, using default HTTP settings\n", err)
+		client, _ = New(Options{Timeout: 30 * time.Second, Retries: 3})
+	}
+	defaultOptions, defaultClient = opts, client
+	return client
+}
+
+// Get fetches url with the default client
+func Get(url string) (*http.Response, error) {
+	return Default().Get(url)
+}
+
+// New builds a client that honors HTTPS_PROXY/NO_PROXY, trusts the extra CA bundle,
+// identifies itself as bluefin-cli and retries idempotent requests
+func New(opts Options) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+
+	if opts.CABundle != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(opts.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", opts.CABundle)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	return &http.Client{
+		Transport: &retryTransport{
+			base:    transport,
+			retries: opts.Retries,
+			timeout: opts.Timeout,
+		},
+	}, nil
+}
+
+// retryTransport sets the User-Agent and retries GET and HEAD requests that fail with a
+// network error, 429 or 5xx, waiting baseDelay, 2*baseDelay, 4*baseDelay, ... Each attempt,
+// including reading its body, is bounded by timeout.
+type retryTransport struct {
+	base    http.RoundTripper
+	retries int
+	timeout time.Duration
+}
+
+// cancelBody releases the timeout of an attempt when its body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", UserAgent())
+	}
+
+	retryable := req.Method == http.MethodGet || req.Method == http.MethodHead
+	delay := baseDelay
+	for attempt := 0; ; attempt++ {
+		attemptReq, cancel := req, context.CancelFunc(func() {})
+		if t.timeout > 0 {
+			ctx, c := context.WithTimeout(req.Context(), t.timeout)
+			attemptReq, cancel = req.WithContext(ctx), c
+		}
+
+		resp, err := t.base.RoundTrip(attemptReq)
+		if !retryable || attempt >= t.retries || !shouldRetry(resp, err) {
+			if resp == nil {
+				cancel()
+				return resp, err
+			}
+			resp.Body = cancelBody{ReadCloser: resp.Body, cancel: cancel}
+			return resp, err
+		}
+
+		wait := delay
+		if resp != nil {
+			if after := retryAfter(resp); after > 0 {
+				wait = after
+			}
+			// Drain so the connection can be reused
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		cancel()
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+		delay *= 2
+	}
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// retryAfter reads a Retry-After header given in seconds, capped at a minute
+func retryAfter(resp *http.Response) time.Duration {
+	secs, err := strconv.Atoi(strings.TrimSpace(resp.Header.Get("Retry-After")))
+	if err != nil || secs <= 0 {
+		return 0
+	}
+	return min(time.Duration(secs)*time.Second, time.Minute)
+}
+
+// systemCABundles are the system trust stores curl uses by default, in the order Go's crypto/x509 looks for them
+var systemCABundles = []string{
+	"/etc/ssl/certs/ca-certificates.crt",                // Debian, Ubuntu, Arch
+	"/etc/pki/tls/certs/ca-bundle.crt",                  // Fedora, RHEL
+	"/etc/pki/ca-trust/extracted/pem/tls-ca-bundle.pem", // Fedora, RHEL
+	"/etc/ssl/ca-bundle.pem",                            // openSUSE
+	"/etc/ssl/cert.pem",                                 // macOS, Alpine
+}
+
+// curlCABundle writes the system trust store followed by the extra CA bundle to ca-bundle.pem
+// in the cache directory, since curl's --cacert replaces the system store rather than adding
+// to it. SSL_CERT_FILE overrides the system store, as it does for Go.
+func curlCABundle(extra string) (string, error) {
+	pem, err := os.ReadFile(extra)
+	if err != nil {
+		return "", fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	candidates := systemCABundles
+	if file := os.Getenv("SSL_CERT_FILE"); file != "" {
+		candidates = []string{file}
+	}
+	var combined []byte
+	for _, path := range candidates {
+		if system, err := os.ReadFile(path); err == nil {
+			combined = append(system, '\n')
+			break
+		}
+	}
+	if combined == nil {
+		return "", fmt.Errorf("no system CA bundle found")
+	}
+	combined = append(combined, pem...)
+
+	dir, err := env.EnsureCacheDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "ca-bundle.pem")
+	if err := env.WriteFileAtomic(path, combined, 0600); err != nil {
+		return "", fmt.Errorf("failed to write CA bundle for curl: %w", err)
+	}
+	return path, nil
+}
+
+// CurlArgs returns curl flags matching the client settings, for upstream install scripts
+// that are piped through curl. The extra CA bundle is passed combined with the system trust
+// store, so public hosts stay trusted.
+func CurlArgs() []string {
+	opts := LoadOptions()
+	args := []string{"--user-agent", UserAgent()}
+	if opts.Timeout > 0 {
+		args = append(args, "--max-time", strconv.Itoa(max(1, int(opts.Timeout.Seconds()))))
+	}
+	if opts.Retries > 0 {
+		args = append(args, "--retry", strconv.Itoa(opts.Retries))
+	}
+	if opts.CABundle != "" {
+		bundle, err := curlCABundle(opts.CABundle)
+		if err != nil {
+			// Without the extra CA curl still reaches public hosts
+			fmt.Fprintf(os.Stderr, "Warning: %v, curl will not trust %s\n", err, opts.CABundle)
+		} else {
+			args = append(args, "--cacert", bundle)
+		}
+	}
+	return args
+}
+
+// CurlCommand returns a shell-quoted curl invocation of url with the given flags and CurlArgs
+func CurlCommand(url string, flags ...string) string {
+	parts := []string{"curl"}
+	for _, arg := range append(append(flags, CurlArgs()...), url) {
+		parts = append(parts, shellQuote(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:@=", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
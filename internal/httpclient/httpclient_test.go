@@ -0,0 +1,178 @@
+package httpclient
+
+import (
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetries(t *testing.T) {
+	baseDelay = time.Millisecond
+	SetVersion("1.2.3")
+
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := attempts.Add(1)
+		if ua := r.Header.Get("User-Agent"); ua != "bluefin-cli/1.2.3" {
+			t.Errorf("Unexpected User-Agent %q", ua)
+		}
+		if n < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	client, err := New(Options{Timeout: 5 * time.Second, Retries: 3})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || attempts.Load() != 3 {
+		t.Errorf("Expected success on the third attempt, got HTTP %d after %d attempts", resp.StatusCode, attempts.Load())
+	}
+
+	// Retries are bounded
+	attempts.Store(-10)
+	resp, err = client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || attempts.Load() != -6 {
+		t.Errorf("Expected to give up after 4 attempts, got HTTP %d, %d attempts", resp.StatusCode, attempts.Load()+10)
+	}
+
+	// Requests with side effects are not retried
+	attempts.Store(0)
+	resp, err = client.Post(srv.URL, "text/plain", strings.NewReader("x"))
+	if err != nil {
+		t.Fatalf("Post() failed: %v", err)
+	}
+	resp.Body.Close()
+	if attempts.Load() != 1 {
+		t.Errorf("Expected a single POST attempt, got %d", attempts.Load())
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	baseDelay = time.Millisecond
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	client, _ := New(Options{Timeout: 20 * time.Millisecond})
+	if _, err := client.Get(srv.URL); err == nil {
+		t.Error("Expected the request to time out")
+	}
+}
+
+func TestTimeoutPerAttempt(t *testing.T) {
+	baseDelay = time.Millisecond
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			time.Sleep(200 * time.Millisecond)
+			return
+		}
+		time.Sleep(30 * time.Millisecond)
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	// The attempts take longer than the timeout together, but each fits in it after the first
+	client, _ := New(Options{Timeout: 100 * time.Millisecond, Retries: 1})
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Expected the retry to succeed: %v", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	// The timed out first attempt's handler is still sleeping; wait for it
+	srv.Close()
+	if err != nil || string(body) != "ok" || attempts.Load() != 2 {
+		t.Errorf("Expected ok on the second attempt, got %q (%v) after %d attempts", body, err, attempts.Load())
+	}
+}
+
+func TestCABundle(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	cert := srv.Certificate()
+	os.WriteFile(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0644)
+
+	client, err := New(Options{Timeout: 5 * time.Second, CABundle: bundle})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Expected the extra CA to be trusted: %v", err)
+	}
+	resp.Body.Close()
+
+	if _, err := New(Options{CABundle: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Error("Expected an error for a missing CA bundle")
+	}
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	os.WriteFile(empty, []byte("not a certificate"), 0644)
+	if _, err := New(Options{CABundle: empty}); err == nil {
+		t.Error("Expected an error for a CA bundle without certificates")
+	}
+}
+
+func TestCurlCommand(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("HOMEBREW_PREFIX", "")
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(t.TempDir(), "my cache"))
+	t.Setenv("SSL_CERT_FILE", "")
+	SetVersion("1.2.3")
+
+	dir := t.TempDir()
+	system := filepath.Join(dir, "system.pem")
+	extra := filepath.Join(dir, "corp.pem")
+	os.WriteFile(system, []byte("SYSTEM CA\n"), 0644)
+	os.WriteFile(extra, []byte("CORPORATE CA\n"), 0644)
+	origBundles := systemCABundles
+	defer func() { systemCABundles = origBundles }()
+	systemCABundles = []string{filepath.Join(dir, "missing.pem"), system}
+	t.Setenv("BLUEFIN_CLI_CA_BUNDLE", extra)
+
+	combined := filepath.Join(os.Getenv("XDG_CACHE_HOME"), "bluefin-cli", "ca-bundle.pem")
+	got := CurlCommand("https://example.com/install.sh", "-fsSL")
+	want := "curl -fsSL --user-agent bluefin-cli/1.2.3 --max-time 30 --retry 3 --cacert '" + combined + "' https://example.com/install.sh"
+	if got != want {
+		t.Errorf("CurlCommand() =\n  %s\nwant\n  %s", got, want)
+	}
+
+	// curl gets the system trust store along with the extra CA, not the extra CA alone
+	data, err := os.ReadFile(combined)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "SYSTEM CA\n\nCORPORATE CA\n" {
+		t.Errorf("Unexpected combined bundle %q", data)
+	}
+
+	// Without a system store the extra CA is left out rather than replacing it
+	systemCABundles = []string{filepath.Join(dir, "missing.pem")}
+	if got := CurlCommand("https://example.com/install.sh"); strings.Contains(got, "--cacert") {
+		t.Errorf("Expected no --cacert without a system CA bundle, got %s", got)
+	}
+}
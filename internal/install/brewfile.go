@@ -0,0 +1,88 @@
+package install
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// BrewfileEntry is a single declaration in a Brewfile, e.g. `brew "gh"` or `flatpak "org.gnome.Boxes"`
+type BrewfileEntry struct {
+	Kind    string `json:"kind"` // tap, brew, cask, flatpak, mas, vscode, ...
+	Name    string `json:"name"`
+	Options string `json:"options,omitempty"` // Anything after the name, e.g. `args: ["HEAD"]`
+}
+
+var brewfileLine = regexp.MustCompile(`^\s*([a-z_]+)\s+["']([^"']+)["']\s*,?\s*(.*?)\s*$`)
+
+// String renders the entry back into Brewfile syntax
+func (e BrewfileEntry) String() string {
+	if e.Options != "" {
+		return fmt.Sprintf("%s %q, %s", e.Kind, e.Name, e.Options)
+	}
+	return fmt.Sprintf("%s %q", e.Kind, e.Name)
+}
+
+// ParseBrewfile reads the entries of a Brewfile, skipping comments and blank lines
+func ParseBrewfile(path string) ([]BrewfileEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return parseBrewfile(f)
+}
+
+// ParseBrewfileString reads the entries of Brewfile content held in memory
+func ParseBrewfileString(content string) ([]BrewfileEntry, error) {
+	return parseBrewfile(strings.NewReader(content))
+}
+
+func parseBrewfile(r io.Reader) ([]BrewfileEntry, error) {
+	var entries []BrewfileEntry
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if e, _, ok := parseBrewfileLine(scanner.Text()); ok {
+			entries = append(entries, e)
+		}
+	}
+
+	return entries, scanner.Err()
+}
+
+// parseBrewfileLine reads one Brewfile line, returning the entry and its trailing comment
+// without the #. ok is false for blank lines, comments and lines that aren't entries.
+func parseBrewfileLine(line string) (entry BrewfileEntry, comment string, ok bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return BrewfileEntry{}, "", false
+	}
+
+	m := brewfileLine.FindStringSubmatch(line)
+	if m == nil {
+		return BrewfileEntry{}, "", false
+	}
+
+	options := m[3]
+	if i := strings.Index(options, "#"); i >= 0 {
+		comment = strings.TrimSpace(options[i+1:])
+		options = strings.TrimSpace(options[:i])
+	}
+	return BrewfileEntry{Kind: m[1], Name: m[2], Options: options}, comment, true
+}
+
+// FilterEntries returns the entries of the given kind
+func FilterEntries(entries []BrewfileEntry, kind string) []BrewfileEntry {
+	var filtered []BrewfileEntry
+	for _, e := range entries {
+		if e.Kind == kind {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,48 @@
+package install
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseBrewfile(t *testing.T) {
+	content := `# AI tools
+tap "ublue-os/tap"
+brew "gh"
+brew "ramalama", args: ["HEAD"] # nightly
+cask "goose-desktop"
+
+flatpak "org.gnome.Boxes"
+  vscode "golang.go"
+`
+
+	entries, err := parseBrewfile(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("parseBrewfile() failed: %v", err)
+	}
+
+	want := []BrewfileEntry{
+		{Kind: "tap", Name: "ublue-os/tap"},
+		{Kind: "brew", Name: "gh"},
+		{Kind: "brew", Name: "ramalama", Options: `args: ["HEAD"]`},
+		{Kind: "cask", Name: "goose-desktop"},
+		{Kind: "flatpak", Name: "org.gnome.Boxes"},
+		{Kind: "vscode", Name: "golang.go"},
+	}
+
+	if len(entries) != len(want) {
+		t.Fatalf("Expected %d entries, got %d: %+v", len(want), len(entries), entries)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("Entry %d = %+v, want %+v", i, entries[i], want[i])
+		}
+	}
+
+	if got := len(FilterEntries(entries, "brew")); got != 2 {
+		t.Errorf("Expected 2 brew entries, got %d", got)
+	}
+	if got := entries[2].String(); got != `brew "ramalama", args: ["HEAD"]` {
+		t.Errorf("Unexpected String(): %s", got)
+	}
+}
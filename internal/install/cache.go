@@ -0,0 +1,306 @@
+package install
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hanthor/bluefin-cli/internal/config"
+	"github.com/hanthor/bluefin-cli/internal/env"
+	"github.com/hanthor/bluefin-cli/internal/httpclient"
+)
+
+// CacheEntry describes a cached download and how to revalidate it
+type CacheEntry struct {
+	File         string    `json:"file"`
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last-modified,omitempty"`
+	Fetched      time.Time `json:"fetched"`
+	SHA256       string    `json:"sha256"`
+	Size         int64     `json:"size"`
+}
+
+const manifestCacheFile = "SHA256SUMS"
+
+// baseURL returns the configured bundle base URL, defaulting to projectbluefin/common
+func baseURL() string {
+	settings, _ := config.Load()
+	if settings.BundleBaseURL != "" {
+		return strings.TrimSuffix(settings.BundleBaseURL, "/")
+	}
+	return commonBaseURL
+}
+
+func bundleURL(bundle BundleSpec) string {
+	path := defaultBrewPath
+	if bundle.Path != "" {
+		path = bundle.Path
+	}
+	return fmt.Sprintf("%s/%s/%s", baseURL(), path, bundle.File)
+}
+
+func bundleCacheDir() (string, error) {
+	dir, err := env.GetCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "bundles"), nil
+}
+
+func metaPath(dir, file string) string {
+	return filepath.Join(dir, file+".json")
+}
+
+func readCacheEntry(dir, file string) (*CacheEntry, bool) {
+	data, err := os.ReadFile(metaPath(dir, file))
+	if err != nil {
+		return nil, false
+	}
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// fetchCached returns the path of an up-to-date cached copy of url. A cached copy is
+// revalidated with its ETag and Last-Modified; when the server cannot be reached it is
+// used as is.
+func fetchCached(url, file string) (string, error) {
+	dir, err := bundleCacheDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create bundle cache: %w", err)
+	}
+
+	path := filepath.Join(dir, file)
+	cached, haveCache := readCacheEntry(dir, file)
+	if haveCache && cached.URL != url {
+		haveCache = false
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	if haveCache {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := httpclient.Default().Do(req)
+	if err != nil {
+		if haveCache {
+			fmt.Fprintln(os.Stderr, infoStyle.Render(fmt.Sprintf("Offline, using cached %s from %s", file, cached.Fetched.Local().Format("2006-01-02 15:04"))))
+			return path, nil
+		}
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && haveCache:
+		cached.Fetched = time.Now()
+		return path, writeCacheEntry(dir, cached)
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode >= 500 && haveCache:
+		fmt.Fprintln(os.Stderr, infoStyle.Render(fmt.Sprintf("Server error (HTTP %d), using cached %s", resp.StatusCode, file)))
+		return path, nil
+	default:
+		return "", fmt.Errorf("failed to download: HTTP %d", resp.StatusCode)
+	}
+
+	// Write next to the cached copy and rename, so an interrupted download never replaces it
+	tmp, err := os.CreateTemp(dir, file+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), resp.Body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to download: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+
+	return path, writeCacheEntry(dir, &CacheEntry{
+		File:         file,
+		URL:          url,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Fetched:      time.Now(),
+		SHA256:       hex.EncodeToString(hash.Sum(nil)),
+		Size:         size,
+	})
+}
+
+func writeCacheEntry(dir string, entry *CacheEntry) error {
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(metaPath(dir, entry.File), data, 0644)
+}
+
+// verifyManifest checks a cached file against the configured SHA-256 manifest, if any
+func verifyManifest(path string) error {
+	settings, _ := config.Load()
+	if settings.BundleManifest == "" {
+		return nil
+	}
+
+	manifestPath, err := fetchCached(settings.BundleManifest, manifestCacheFile)
+	if err != nil {
+		return fmt.Errorf("failed to fetch bundle manifest: %w", err)
+	}
+	sums, err := parseManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(path)
+	want, ok := sums[name]
+	if !ok {
+		return fmt.Errorf("%s is not listed in the bundle manifest", name)
+	}
+	got, err := fileSHA256(path)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(got, want) {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", name, want, got)
+	}
+	return nil
+}
+
+// parseManifest reads sha256sum output: "<hex digest>  <file name>" per line
+func parseManifest(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		sums[filepath.Base(strings.TrimPrefix(fields[1], "*"))] = fields[0]
+	}
+	return sums, scanner.Err()
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// fetchBundle returns the cached Brewfile of a bundle, downloading or revalidating it first
+func fetchBundle(bundle BundleSpec) (string, error) {
+	path, err := fetchCached(bundleURL(bundle), bundle.File)
+	if err != nil {
+		return "", err
+	}
+	if err := verifyManifest(path); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+// CachedBundles lists the cached Brewfiles, sorted by file name
+func CachedBundles() ([]CacheEntry, error) {
+	dir, err := bundleCacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []CacheEntry
+	for _, m := range matches {
+		file := strings.TrimSuffix(filepath.Base(m), ".json")
+		if file == manifestCacheFile {
+			continue
+		}
+		if entry, ok := readCacheEntry(dir, file); ok {
+			entries = append(entries, *entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].File < entries[j].File })
+	return entries, nil
+}
+
+// ClearCache deletes every cached Brewfile and manifest
+func ClearCache() error {
+	dir, err := bundleCacheDir()
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to clear bundle cache: %w", err)
+	}
+	return nil
+}
+
+// RefreshCache revalidates the given bundles, or every bundle when names is empty
+func RefreshCache(names []string) error {
+	if len(names) == 0 {
+		for name := range bundles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	for _, name := range names {
+		bundle, ok := bundles[name]
+		if !ok {
+			return fmt.Errorf("unknown bundle: %s", name)
+		}
+		if _, err := fetchBundle(bundle); err != nil {
+			return fmt.Errorf("failed to refresh %s: %w", name, err)
+		}
+		fmt.Println(successStyle.Render(fmt.Sprintf("✓ %s", bundle.File)))
+	}
+	return nil
+}
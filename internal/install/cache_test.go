@@ -0,0 +1,151 @@
+package install
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testBrewfile = "brew \"kubectl\"\n"
+
+// withBundleServer serves testBrewfile for every bundle with an ETag and points the cache at temp dirs
+func withBundleServer(t *testing.T) (*httptest.Server, *int) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOMEBREW_PREFIX", "")
+	// Fail fast when the server is gone instead of backing off
+	writeTestConfig(t, `{"http-retries": 0}`)
+
+	downloads := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(testBrewfile))
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv("BLUEFIN_CLI_BUNDLE_BASE_URL", srv.URL)
+	return srv, &downloads
+}
+
+func TestGetBrewfileCache(t *testing.T) {
+	srv, downloads := withBundleServer(t)
+
+	path, cleanup, err := GetBrewfile("k8s")
+	if err != nil {
+		t.Fatalf("GetBrewfile() failed: %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	cleanup()
+	if string(content) != testBrewfile {
+		t.Errorf("Unexpected Brewfile content: %q", content)
+	}
+
+	// A second fetch revalidates with the ETag instead of downloading again
+	if _, cleanup, err := GetBrewfile("k8s"); err != nil {
+		t.Fatalf("GetBrewfile() revalidation failed: %v", err)
+	} else {
+		cleanup()
+	}
+	if *downloads != 1 {
+		t.Errorf("Expected 1 download, got %d", *downloads)
+	}
+
+	entries, err := CachedBundles()
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("CachedBundles() = %v, %v", entries, err)
+	}
+	sum := sha256.Sum256([]byte(testBrewfile))
+	if entries[0].File != "k8s-tools.Brewfile" || entries[0].ETag != `"v1"` || entries[0].SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("Unexpected cache entry: %+v", entries[0])
+	}
+
+	// Offline, the cached copy is used
+	srv.Close()
+	if _, cleanup, err := GetBrewfile("k8s"); err != nil {
+		t.Fatalf("Expected offline fallback, got %v", err)
+	} else {
+		cleanup()
+	}
+	if _, _, err := GetBrewfile("cli"); err == nil {
+		t.Error("Expected an error for an uncached bundle while offline")
+	}
+
+	if err := ClearCache(); err != nil {
+		t.Fatalf("ClearCache() failed: %v", err)
+	}
+	if entries, _ := CachedBundles(); len(entries) != 0 {
+		t.Errorf("Expected empty cache after clear, got %v", entries)
+	}
+}
+
+func TestFetchCachedErrors(t *testing.T) {
+	srv, _ := withBundleServer(t)
+
+	missing := httptest.NewServer(http.NotFoundHandler())
+	defer missing.Close()
+	if _, err := fetchCached(missing.URL+"/missing.Brewfile", "missing.Brewfile"); err == nil || !strings.Contains(err.Error(), "HTTP 404") {
+		t.Errorf("Expected an HTTP 404 error, got %v", err)
+	}
+	if _, err := fetchCached("https://invalid.invalid/nonexistent.Brewfile", "nonexistent.Brewfile"); err == nil {
+		t.Error("Expected an error for an unreachable host")
+	}
+
+	path, err := fetchCached(srv.URL+"/cli.Brewfile", "cli.Brewfile")
+	if err != nil {
+		t.Fatalf("fetchCached() failed: %v", err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != testBrewfile {
+		t.Errorf("Unexpected content %q", content)
+	}
+}
+
+func TestBundleManifest(t *testing.T) {
+	withBundleServer(t)
+
+	sum := sha256.Sum256([]byte(testBrewfile))
+	manifest := filepath.Join(t.TempDir(), "SHA256SUMS")
+	manifestSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, manifest)
+	}))
+	defer manifestSrv.Close()
+
+	writeTestConfig(t, `{"http-retries": 0, "bundle-manifest": "`+manifestSrv.URL+`"}`)
+
+	os.WriteFile(manifest, []byte(hex.EncodeToString(sum[:])+"  k8s-tools.Brewfile\n"), 0644)
+	if _, cleanup, err := GetBrewfile("k8s"); err != nil {
+		t.Fatalf("Expected matching checksum to pass, got %v", err)
+	} else {
+		cleanup()
+	}
+
+	if _, _, err := GetBrewfile("cli"); err == nil {
+		t.Error("Expected an error for a Brewfile missing from the manifest")
+	}
+
+	os.WriteFile(manifest, []byte("0000  k8s-tools.Brewfile\n"), 0644)
+	ClearCache()
+	if _, _, err := GetBrewfile("k8s"); err == nil {
+		t.Error("Expected a checksum mismatch error")
+	}
+}
+
+func writeTestConfig(t *testing.T, config string) {
+	t.Helper()
+	configDir := filepath.Join(os.Getenv("HOME"), ".config", "bluefin-cli")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "config.json"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+}
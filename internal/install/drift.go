@@ -0,0 +1,204 @@
+package install
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Bundle item states reported by CheckBundle
+const (
+	StateInstalled = "installed"
+	StateMissing   = "missing"
+	StateOutdated  = "outdated"
+)
+
+// BundleItem is the install state of one Brewfile entry
+type BundleItem struct {
+	Kind             string `json:"kind"`
+	Name             string `json:"name"`
+	State            string `json:"state"`
+	InstalledVersion string `json:"installed-version,omitempty"`
+	LatestVersion    string `json:"latest-version,omitempty"`
+}
+
+// BundleStatus compares a bundle's Brewfile with what is installed
+type BundleStatus struct {
+	Bundle string       `json:"bundle"`
+	Items  []BundleItem `json:"items"`
+}
+
+// Count returns the number of items in the given state
+func (s BundleStatus) Count(state string) int {
+	n := 0
+	for _, item := range s.Items {
+		if item.State == state {
+			n++
+		}
+	}
+	return n
+}
+
+// Percent is the share of items that are installed, counting outdated ones
+func (s BundleStatus) Percent() int {
+	if len(s.Items) == 0 {
+		return 100
+	}
+	return 100 * (len(s.Items) - s.Count(StateMissing)) / len(s.Items)
+}
+
+// CheckBundle resolves a bundle or Brewfile and reports which of its formulae, casks and
+// flatpaks are installed, missing or outdated. Outdated checks are skipped unless requested,
+// as they are slower.
+func CheckBundle(nameOrPath string, checkOutdated bool) (*BundleStatus, error) {
+	brewfilePath, cleanup, err := GetBrewfile(nameOrPath)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	entries, err := ParseBrewfile(brewfilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var outdated map[string]string
+	if checkOutdated {
+		outdated = outdatedPackages()
+	}
+
+	status := compareBundle(BundleName(nameOrPath), entries, TakeSnapshot(), outdated)
+	return &status, nil
+}
+
+// CachedCoverage reports which items of the named bundles are installed, reading only the
+// cached Brewfiles and taking one snapshot for all of them. Bundles that are unknown or not
+// cached are skipped, so it never touches the network.
+func CachedCoverage(names []string) []BundleStatus {
+	dir, err := bundleCacheDir()
+	if err != nil {
+		return nil
+	}
+
+	var installed Snapshot
+	var statuses []BundleStatus
+	for _, name := range names {
+		bundle, ok := bundles[name]
+		if !ok {
+			continue
+		}
+		if _, ok := readCacheEntry(dir, bundle.File); !ok {
+			continue
+		}
+		entries, err := ParseBrewfile(filepath.Join(dir, bundle.File))
+		if err != nil {
+			continue
+		}
+		if installed == nil {
+			installed = TakeSnapshot()
+		}
+		statuses = append(statuses, compareBundle(name, entries, installed, nil))
+	}
+	return statuses
+}
+
+// compareBundle classifies each formula, cask and flatpak entry against the installed snapshot
+// and the latest versions of outdated packages, both keyed like Snapshot
+func compareBundle(bundle string, entries []BrewfileEntry, installed Snapshot, outdated map[string]string) BundleStatus {
+	status := BundleStatus{Bundle: bundle, Items: []BundleItem{}}
+
+	for _, e := range entries {
+		var keys []string
+		name := e.Name
+		switch e.Kind {
+		case "brew", "cask":
+			name = name[strings.LastIndex(name, "/")+1:]
+			keys = []string{snapshotKey("brew", name)}
+		case "flatpak":
+			keys = []string{snapshotKey(Flatpak{Scope: FlatpakUser}.Name(), name), snapshotKey(Flatpak{Scope: FlatpakSystem}.Name(), name)}
+		default:
+			continue
+		}
+
+		item := BundleItem{Kind: e.Kind, Name: e.Name, State: StateMissing}
+		for _, key := range keys {
+			version, ok := installed[key]
+			if !ok {
+				continue
+			}
+			item.State = StateInstalled
+			item.InstalledVersion = version
+			if latest, ok := outdated[key]; ok {
+				item.State = StateOutdated
+				item.LatestVersion = latest
+			}
+			break
+		}
+		status.Items = append(status.Items, item)
+	}
+
+	return status
+}
+
+// outdatedPackages maps outdated formulae, casks and flatpaks to their latest version
+func outdatedPackages() map[string]string {
+	outdated := make(map[string]string)
+
+	if out, err := exec.Command("brew", "outdated", "--json=v2").Output(); err == nil {
+		if brew, err := parseBrewOutdated(out); err == nil {
+			for name, version := range brew {
+				outdated[snapshotKey("brew", name)] = version
+			}
+		}
+	}
+
+	if CheckFlatpak() == nil {
+		for _, scope := range []FlatpakScope{FlatpakUser, FlatpakSystem} {
+			out, err := exec.Command("flatpak", "remote-ls", "--updates", "--app", scope.flag(), "--columns=application,version").Output()
+			if err != nil {
+				continue
+			}
+			for _, line := range strings.Split(string(out), "\n") {
+				fields := strings.Split(line, "\t")
+				if fields[0] == "" {
+					continue
+				}
+				version := ""
+				if len(fields) > 1 {
+					version = strings.TrimSpace(fields[1])
+				}
+				outdated[snapshotKey(Flatpak{Scope: scope}.Name(), fields[0])] = version
+			}
+		}
+	}
+
+	return outdated
+}
+
+// parseBrewOutdated reads the formulae and casks of `brew outdated --json=v2`
+func parseBrewOutdated(data []byte) (map[string]string, error) {
+	var report struct {
+		Formulae []struct {
+			Name           string `json:"name"`
+			CurrentVersion string `json:"current_version"`
+		} `json:"formulae"`
+		Casks []struct {
+			Name           string `json:"name"`
+			CurrentVersion string `json:"current_version"`
+		} `json:"casks"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse brew outdated output: %w", err)
+	}
+
+	outdated := make(map[string]string)
+	for _, f := range report.Formulae {
+		outdated[f.Name] = f.CurrentVersion
+	}
+	for _, c := range report.Casks {
+		outdated[c.Name] = c.CurrentVersion
+	}
+	return outdated, nil
+}
@@ -0,0 +1,98 @@
+package install
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompareBundle(t *testing.T) {
+	entries := []BrewfileEntry{
+		{Kind: "tap", Name: "derailed/k9s"},
+		{Kind: "brew", Name: "derailed/k9s/k9s"},
+		{Kind: "brew", Name: "kubectl"},
+		{Kind: "cask", Name: "lens"},
+		{Kind: "flatpak", Name: "org.gnome.Boxes"},
+		{Kind: "vscode", Name: "ms-kubernetes-tools.vscode-kubernetes-tools"},
+	}
+	installed := Snapshot{
+		"brew:k9s":                       "0.32.4",
+		"brew:kubectl":                   "1.31.0",
+		"flatpak-system:org.gnome.Boxes": "46.1",
+	}
+	outdated := map[string]string{"brew:k9s": "0.32.5"}
+
+	status := compareBundle("k8s", entries, installed, outdated)
+	if len(status.Items) != 4 {
+		t.Fatalf("Expected 4 items (taps and vscode skipped), got %+v", status.Items)
+	}
+
+	want := map[string]string{
+		"derailed/k9s/k9s": StateOutdated,
+		"kubectl":          StateInstalled,
+		"lens":             StateMissing,
+		"org.gnome.Boxes":  StateInstalled,
+	}
+	for _, item := range status.Items {
+		if item.State != want[item.Name] {
+			t.Errorf("%s: state = %s, want %s", item.Name, item.State, want[item.Name])
+		}
+	}
+	if k9s := status.Items[0]; k9s.InstalledVersion != "0.32.4" || k9s.LatestVersion != "0.32.5" {
+		t.Errorf("Unexpected k9s versions: %+v", k9s)
+	}
+
+	if status.Count(StateMissing) != 1 || status.Percent() != 75 {
+		t.Errorf("Count(missing) = %d, Percent() = %d", status.Count(StateMissing), status.Percent())
+	}
+	if (BundleStatus{}).Percent() != 100 {
+		t.Error("Expected an empty bundle to be fully installed")
+	}
+}
+
+func TestParseBrewOutdated(t *testing.T) {
+	data := []byte(`{"formulae":[{"name":"k9s","installed_versions":["0.32.4"],"current_version":"0.32.5"}],
+"casks":[{"name":"lens","installed_versions":"6.0.0","current_version":"6.1.0"}]}`)
+
+	outdated, err := parseBrewOutdated(data)
+	if err != nil {
+		t.Fatalf("parseBrewOutdated() failed: %v", err)
+	}
+	if outdated["k9s"] != "0.32.5" || outdated["lens"] != "6.1.0" {
+		t.Errorf("Unexpected outdated versions: %v", outdated)
+	}
+
+	if _, err := parseBrewOutdated([]byte("Error: not json")); err == nil {
+		t.Error("Expected an error for invalid output")
+	}
+}
+
+func TestCachedCoverage(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	// The snapshot finds nothing without brew or flatpak on PATH
+	t.Setenv("PATH", t.TempDir())
+
+	dir, err := bundleCacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	file := bundles["cli"].File
+	if err := os.WriteFile(filepath.Join(dir, file), []byte("brew \"gh\"\nbrew \"chezmoi\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeCacheEntry(dir, &CacheEntry{File: file}); err != nil {
+		t.Fatal(err)
+	}
+
+	// ai is not cached and my-tools is not a bundle, so only cli is reported
+	statuses := CachedCoverage([]string{"ai", "cli", "my-tools"})
+	if len(statuses) != 1 || statuses[0].Bundle != "cli" {
+		t.Fatalf("Expected only the cached bundle, got %+v", statuses)
+	}
+	if len(statuses[0].Items) != 2 || statuses[0].Percent() != 0 {
+		t.Errorf("Expected 2 missing items, got %+v", statuses[0].Items)
+	}
+}
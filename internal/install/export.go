@@ -0,0 +1,160 @@
+package install
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hanthor/bluefin-cli/internal/ledger"
+)
+
+// ExportBrewfile writes a Brewfile of the installed taps, formulae installed on request,
+// casks and flatpaks. Each package is annotated with the bundle it was installed from,
+// according to the ledger or, failing that, the bundle snapshots.
+func ExportBrewfile(w io.Writer) error {
+	if err := EnsureBrew(); err != nil {
+		return err
+	}
+
+	taps, err := brewLines("tap")
+	if err != nil {
+		return fmt.Errorf("failed to list taps: %w", err)
+	}
+	formulae, err := brewLines("leaves", "--installed-on-request")
+	if err != nil {
+		return fmt.Errorf("failed to list formulae: %w", err)
+	}
+	casks, err := brewLines("list", "--cask", "-1")
+	if err != nil {
+		return fmt.Errorf("failed to list casks: %w", err)
+	}
+	// Flatpak is optional, e.g. on macOS
+	flatpaks, _ := ListFlatpaks()
+
+	_, err = io.WriteString(w, formatExport(taps, formulae, casks, flatpaks, bundleOrigins()))
+	return err
+}
+
+func brewLines(args ...string) ([]string, error) {
+	out, err := exec.Command("brew", args...).Output()
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// bundleOrigins maps installed packages, keyed like Snapshot, to the bundle that installed them
+func bundleOrigins() map[string]string {
+	origins := make(map[string]string)
+
+	// Bundle snapshots cover packages installed before the ledger existed
+	for bundle := range bundles {
+		snap, err := LoadBundleSnapshot(bundle)
+		if err != nil {
+			continue
+		}
+		for _, e := range snap.Entries {
+			short := e.Name[strings.LastIndex(e.Name, "/")+1:]
+			switch e.Kind {
+			case "brew", "cask":
+				origins[snapshotKey("brew", short)] = bundle
+			case "flatpak":
+				origins[snapshotKey(Flatpak{Scope: FlatpakUser}.Name(), e.Name)] = bundle
+				origins[snapshotKey(Flatpak{Scope: FlatpakSystem}.Name(), e.Name)] = bundle
+			}
+		}
+	}
+
+	if installed, err := ledger.Installed(); err == nil {
+		for _, e := range installed {
+			if e.Bundle != "" {
+				origins[snapshotKey(e.Backend, e.Package)] = e.Bundle
+			}
+		}
+	}
+	return origins
+}
+
+// formatExport renders the Brewfile written by ExportBrewfile, sorted within each kind
+func formatExport(taps, formulae, casks []string, flatpaks []FlatpakApp, origins map[string]string) string {
+	var b strings.Builder
+	b.WriteString("# Exported by bluefin-cli\n")
+
+	line := func(kind, name, key string) {
+		entry := BrewfileEntry{Kind: kind, Name: name}.String()
+		if bundle := origins[key]; bundle != "" {
+			fmt.Fprintf(&b, "%-60s # bundle: %s\n", entry, bundle)
+		} else {
+			fmt.Fprintln(&b, entry)
+		}
+	}
+
+	for _, tap := range sorted(taps) {
+		line("tap", tap, "")
+	}
+	for _, f := range sorted(formulae) {
+		line("brew", f, snapshotKey("brew", f[strings.LastIndex(f, "/")+1:]))
+	}
+	for _, c := range sorted(casks) {
+		line("cask", c, snapshotKey("brew", c[strings.LastIndex(c, "/")+1:]))
+	}
+
+	seen := make(map[string]bool)
+	sort.Slice(flatpaks, func(i, j int) bool { return flatpaks[i].ID < flatpaks[j].ID })
+	for _, app := range flatpaks {
+		if seen[app.ID] {
+			continue
+		}
+		seen[app.ID] = true
+		line("flatpak", app.ID, snapshotKey(Flatpak{Scope: app.Scope}.Name(), app.ID))
+	}
+
+	return b.String()
+}
+
+// SplitExport writes the entries of a Brewfile written by ExportBrewfile to one private
+// temporary Brewfile per "# bundle:" annotation, so installs are recorded against the
+// bundles the packages originally came from. Packages without an annotation are
+// attributed to fallback. The files are partial, so bundle snapshots are left alone.
+func SplitExport(content, fallback string) ([]BundleFile, func(), error) {
+	groups := make(map[string][]BrewfileEntry)
+	var order []string
+	for _, line := range strings.Split(content, "\n") {
+		e, comment, ok := parseBrewfileLine(line)
+		if !ok || !e.IsPackage() {
+			continue
+		}
+		bundle := fallback
+		if name, found := strings.CutPrefix(comment, "bundle:"); found && strings.TrimSpace(name) != "" {
+			bundle = strings.TrimSpace(name)
+		}
+		if _, seen := groups[bundle]; !seen {
+			order = append(order, bundle)
+		}
+		groups[bundle] = append(groups[bundle], e)
+	}
+
+	tmpDir, cleanup, err := privateTempDir()
+	if err != nil {
+		return nil, func() {}, err
+	}
+	var files []BundleFile
+	for i, bundle := range order {
+		path := filepath.Join(tmpDir, fmt.Sprintf("%d.Brewfile", i))
+		if err := writeBrewfile(path, groups[bundle]); err != nil {
+			cleanup()
+			return nil, func() {}, err
+		}
+		files = append(files, BundleFile{Name: bundle, Path: path, Partial: true})
+	}
+	return files, cleanup, nil
+}
+
+func sorted(names []string) []string {
+	out := append([]string(nil), names...)
+	sort.Strings(out)
+	return out
+}
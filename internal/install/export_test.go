@@ -0,0 +1,93 @@
+package install
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatExport(t *testing.T) {
+	flatpaks := []FlatpakApp{
+		{ID: "org.gnome.Boxes", Scope: FlatpakSystem},
+		{ID: "com.github.tchx84.Flatseal", Scope: FlatpakUser},
+		{ID: "org.gnome.Boxes", Scope: FlatpakUser},
+	}
+	origins := map[string]string{
+		"brew:k9s":                       "k8s",
+		"brew:lens":                      "k8s",
+		"flatpak-system:org.gnome.Boxes": "full-desktop",
+	}
+
+	out := formatExport([]string{"derailed/k9s"}, []string{"kubectl", "derailed/k9s/k9s"}, []string{"lens"}, flatpaks, origins)
+
+	entries, err := ParseBrewfileString(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Kind+" "+e.Name)
+	}
+	want := []string{
+		"tap derailed/k9s",
+		"brew derailed/k9s/k9s",
+		"brew kubectl",
+		"cask lens",
+		"flatpak com.github.tchx84.Flatseal",
+		"flatpak org.gnome.Boxes",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected entries:\n%s", strings.Join(got, "\n"))
+	}
+
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case strings.HasPrefix(line, `brew "derailed/k9s/k9s"`), strings.HasPrefix(line, `cask "lens"`):
+			if !strings.HasSuffix(line, "# bundle: k8s") {
+				t.Errorf("Expected k8s annotation: %q", line)
+			}
+		case strings.HasPrefix(line, `flatpak "org.gnome.Boxes"`):
+			if !strings.HasSuffix(line, "# bundle: full-desktop") {
+				t.Errorf("Expected full-desktop annotation: %q", line)
+			}
+		case strings.HasPrefix(line, `brew "kubectl"`):
+			if strings.Contains(line, "#") {
+				t.Errorf("Expected no annotation: %q", line)
+			}
+		}
+	}
+}
+
+func TestSplitExport(t *testing.T) {
+	out := formatExport([]string{"derailed/k9s"}, []string{"kubectl", "derailed/k9s/k9s"}, []string{"lens"}, []FlatpakApp{{ID: "org.gnome.Boxes", Scope: FlatpakSystem}},
+		map[string]string{"brew:k9s": "k8s", "brew:lens": "k8s", "flatpak-system:org.gnome.Boxes": "full-desktop"})
+
+	files, cleanup, err := SplitExport(out, "laptop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	var got []string
+	for _, f := range files {
+		if !f.Partial {
+			t.Errorf("Expected %s to be partial", f.Name)
+		}
+		entries, err := ParseBrewfile(f.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name)
+		}
+		got = append(got, f.Name+": "+strings.Join(names, ","))
+	}
+	want := []string{
+		"k8s: derailed/k9s/k9s,lens",
+		"laptop: kubectl",
+		"full-desktop: org.gnome.Boxes",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected split:\n%s", strings.Join(got, "\n"))
+	}
+}
@@ -0,0 +1,231 @@
+package install
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/hanthor/bluefin-cli/internal/config"
+	"github.com/hanthor/bluefin-cli/internal/pkgmgr"
+)
+
+// FlatpakScope selects the flatpak installation to operate on
+type FlatpakScope string
+
+const (
+	FlatpakUser   FlatpakScope = "user"
+	FlatpakSystem FlatpakScope = "system"
+)
+
+const flathubURL = "https://dl.flathub.org/repo/flathub.flatpakrepo"
+
+// FlatpakApp is an installed flatpak application
+type FlatpakApp struct {
+	ID      string
+	Name    string
+	Version string
+	Origin  string
+	Scope   FlatpakScope
+}
+
+// Flatpak is the package backend for flatpak applications in a single scope
+type Flatpak struct {
+	Scope FlatpakScope
+}
+
+var _ pkgmgr.PackageManager = Flatpak{}
+
+func init() {
+	pkgmgr.Register(Flatpak{Scope: FlatpakUser})
+	pkgmgr.Register(Flatpak{Scope: FlatpakSystem})
+}
+
+// ParseFlatpakScope validates a scope name, defaulting to the user installation
+func ParseFlatpakScope(s string) (FlatpakScope, error) {
+	switch FlatpakScope(s) {
+	case "", FlatpakUser:
+		return FlatpakUser, nil
+	case FlatpakSystem:
+		return FlatpakSystem, nil
+	default:
+		return "", fmt.Errorf("invalid flatpak scope %q (expected user or system)", s)
+	}
+}
+
+// DefaultFlatpakScope returns the scope configured in config.json
+func DefaultFlatpakScope() FlatpakScope {
+	settings, _ := config.Load()
+	scope, err := ParseFlatpakScope(settings.FlatpakScope)
+	if err != nil {
+		return FlatpakUser
+	}
+	return scope
+}
+
+func (s FlatpakScope) flag() string {
+	return "--" + string(s)
+}
+
+func (f Flatpak) scope() FlatpakScope {
+	if f.Scope == "" {
+		return FlatpakUser
+	}
+	return f.Scope
+}
+
+// Name is "flatpak" for the user installation and "flatpak-system" for the system one
+func (f Flatpak) Name() string {
+	if f.scope() == FlatpakSystem {
+		return "flatpak-system"
+	}
+	return "flatpak"
+}
+
+func (Flatpak) Detect() bool {
+	return CheckFlatpak() == nil
+}
+
+func (f Flatpak) IsInstalled(id string) bool {
+	return exec.Command("flatpak", "info", f.scope().flag(), id).Run() == nil
+}
+
+func (f Flatpak) Install(ids ...string) error {
+	return InstallFlatpaks(ids, f.scope())
+}
+
+func (f Flatpak) Uninstall(ids ...string) error {
+	return runFlatpak(append([]string{"uninstall", "-y", "--noninteractive", f.scope().flag()}, ids...)...)
+}
+
+func (f Flatpak) Version(id string) (string, error) {
+	apps, err := ListFlatpaks()
+	if err != nil {
+		return "", err
+	}
+	for _, app := range apps {
+		if app.ID == id && app.Scope == f.scope() {
+			return app.Version, nil
+		}
+	}
+	return "", fmt.Errorf("%s is not installed via flatpak", id)
+}
+
+// ListFlatpaks returns the applications installed in both user and system scope
+func ListFlatpaks() ([]FlatpakApp, error) {
+	if err := CheckFlatpak(); err != nil {
+		return nil, fmt.Errorf("flatpak not found")
+	}
+
+	out, err := exec.Command("flatpak", "list", "--app", "--columns=application,name,version,origin,installation").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list flatpaks: %w", err)
+	}
+
+	var apps []FlatpakApp
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 5 || fields[0] == "" {
+			continue
+		}
+		apps = append(apps, FlatpakApp{
+			ID:      fields[0],
+			Name:    fields[1],
+			Version: fields[2],
+			Origin:  fields[3],
+			Scope:   FlatpakScope(strings.TrimSpace(fields[4])),
+		})
+	}
+
+	return apps, nil
+}
+
+// CountFlatpaks returns the number of installed applications per scope
+func CountFlatpaks() (map[FlatpakScope]int, error) {
+	apps, err := ListFlatpaks()
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[FlatpakScope]int)
+	for _, app := range apps {
+		counts[app.Scope]++
+	}
+	return counts, nil
+}
+
+// InstallFlatpaks installs applications from Flathub into the given scope
+func InstallFlatpaks(ids []string, scope FlatpakScope) error {
+	if len(ids) == 0 {
+		return fmt.Errorf("no flatpak applications selected")
+	}
+	if err := ensureFlathub(scope); err != nil {
+		return err
+	}
+
+	fmt.Println(infoStyle.Render(fmt.Sprintf("📦 Installing %s (%s)...", strings.Join(ids, ", "), scope)))
+	args := append([]string{"install", "-y", "--noninteractive", scope.flag(), "flathub"}, ids...)
+	if err := runFlatpak(args...); err != nil {
+		return fmt.Errorf("failed to install flatpaks: %w", err)
+	}
+	fmt.Println(successStyle.Render("✓ Flatpaks installed!"))
+	return nil
+}
+
+// UpdateFlatpaks updates the given applications, or every application in scope when ids is empty
+func UpdateFlatpaks(ids []string, scope FlatpakScope) error {
+	if err := CheckFlatpak(); err != nil {
+		return fmt.Errorf("flatpak not found. Please install flatpak first: https://flatpak.org/setup/")
+	}
+
+	args := append([]string{"update", "-y", "--noninteractive", scope.flag()}, ids...)
+	if err := runFlatpak(args...); err != nil {
+		return fmt.Errorf("failed to update flatpaks: %w", err)
+	}
+	fmt.Println(successStyle.Render("✓ Flatpaks updated!"))
+	return nil
+}
+
+// InstallBrewfileFlatpaks installs the `flatpak "..."` entries of a Brewfile that are not installed yet
+func InstallBrewfileFlatpaks(brewfilePath string, scope FlatpakScope) error {
+	entries, err := ParseBrewfile(brewfilePath)
+	if err != nil {
+		return err
+	}
+
+	flatpaks := FilterEntries(entries, "flatpak")
+	if len(flatpaks) == 0 {
+		return nil
+	}
+	if err := CheckFlatpak(); err != nil {
+		fmt.Println(infoStyle.Render(fmt.Sprintf("Skipping %d flatpak entries: flatpak not found", len(flatpaks))))
+		return nil
+	}
+
+	installed := make(map[string]bool)
+	apps, err := ListFlatpaks()
+	if err != nil {
+		return err
+	}
+	for _, app := range apps {
+		installed[app.ID] = true
+	}
+
+	var missing []string
+	for _, e := range flatpaks {
+		if !installed[e.Name] {
+			missing = append(missing, e.Name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return InstallFlatpaks(missing, scope)
+}
+
+func runFlatpak(args ...string) error {
+	cmd := exec.Command("flatpak", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
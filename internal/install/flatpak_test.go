@@ -0,0 +1,140 @@
+package install
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const fakeFlatpakScript = `#!/bin/sh
+echo "$@" >> "$FLATPAK_LOG"
+case "$1" in
+list)
+	printf 'org.gnome.Boxes\tBoxes\t46.1\tflathub\tuser\n'
+	printf 'org.mozilla.firefox\tFirefox\t128.0\tflathub\tsystem\n'
+	printf 'com.github.tchx84.Flatseal\tFlatseal\t2.2.0\tflathub\tuser\n'
+	;;
+remote-list)
+	echo "flathub"
+	;;
+info)
+	[ "$3" = "org.gnome.Boxes" ]
+	;;
+esac
+`
+
+// withFakeFlatpak puts a fake flatpak binary first on PATH and returns the path of its call log
+func withFakeFlatpak(t *testing.T) string {
+	t.Helper()
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "flatpak"), []byte(fakeFlatpakScript), 0755); err != nil {
+		t.Fatalf("Failed to write fake flatpak: %v", err)
+	}
+	logPath := filepath.Join(t.TempDir(), "flatpak.log")
+	t.Setenv("FLATPAK_LOG", logPath)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return logPath
+}
+
+func readLog(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read flatpak log: %v", err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestListFlatpaks(t *testing.T) {
+	withFakeFlatpak(t)
+
+	apps, err := ListFlatpaks()
+	if err != nil {
+		t.Fatalf("ListFlatpaks() failed: %v", err)
+	}
+	if len(apps) != 3 {
+		t.Fatalf("Expected 3 apps, got %d", len(apps))
+	}
+	if apps[1].ID != "org.mozilla.firefox" || apps[1].Scope != FlatpakSystem || apps[1].Version != "128.0" {
+		t.Errorf("Unexpected app parsed: %+v", apps[1])
+	}
+
+	counts, err := CountFlatpaks()
+	if err != nil {
+		t.Fatalf("CountFlatpaks() failed: %v", err)
+	}
+	if counts[FlatpakUser] != 2 || counts[FlatpakSystem] != 1 {
+		t.Errorf("Unexpected counts: %v", counts)
+	}
+}
+
+func TestInstallFlatpaksScope(t *testing.T) {
+	logPath := withFakeFlatpak(t)
+
+	if err := InstallFlatpaks([]string{"org.gnome.Boxes"}, FlatpakSystem); err != nil {
+		t.Fatalf("InstallFlatpaks() failed: %v", err)
+	}
+
+	calls := readLog(t, logPath)
+	last := calls[len(calls)-1]
+	if last != "install -y --noninteractive --system flathub org.gnome.Boxes" {
+		t.Errorf("Unexpected install call: %q", last)
+	}
+}
+
+func TestUpdateFlatpaks(t *testing.T) {
+	logPath := withFakeFlatpak(t)
+
+	if err := UpdateFlatpaks(nil, FlatpakUser); err != nil {
+		t.Fatalf("UpdateFlatpaks() failed: %v", err)
+	}
+
+	calls := readLog(t, logPath)
+	if calls[len(calls)-1] != "update -y --noninteractive --user" {
+		t.Errorf("Unexpected update call: %q", calls[len(calls)-1])
+	}
+}
+
+func TestFlatpakBackend(t *testing.T) {
+	withFakeFlatpak(t)
+
+	backend := Flatpak{Scope: FlatpakUser}
+	if !backend.Detect() {
+		t.Fatal("Expected flatpak backend to be detected")
+	}
+	if !backend.IsInstalled("org.gnome.Boxes") {
+		t.Error("Expected org.gnome.Boxes to be installed")
+	}
+	if backend.IsInstalled("org.gnome.Maps") {
+		t.Error("Expected org.gnome.Maps to be missing")
+	}
+
+	version, err := backend.Version("com.github.tchx84.Flatseal")
+	if err != nil || version != "2.2.0" {
+		t.Errorf("Version() = %q, %v; want 2.2.0", version, err)
+	}
+}
+
+func TestInstallBrewfileFlatpaks(t *testing.T) {
+	logPath := withFakeFlatpak(t)
+
+	brewfile := filepath.Join(t.TempDir(), "Brewfile")
+	content := `brew "gh"
+flatpak "org.gnome.Boxes"
+flatpak "org.gnome.Maps"
+`
+	if err := os.WriteFile(brewfile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write Brewfile: %v", err)
+	}
+
+	if err := InstallBrewfileFlatpaks(brewfile, FlatpakUser); err != nil {
+		t.Fatalf("InstallBrewfileFlatpaks() failed: %v", err)
+	}
+
+	calls := readLog(t, logPath)
+	last := calls[len(calls)-1]
+	if last != "install -y --noninteractive --user flathub org.gnome.Maps" {
+		t.Errorf("Expected only the missing flatpak to be installed, got %q", last)
+	}
+}
@@ -3,14 +3,15 @@ package install
 import (
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/hanthor/bluefin-cli/internal/policy"
 )
 
 var (
@@ -67,7 +68,8 @@ var bundles = map[string]BundleSpec{
 	},
 }
 
-func Bundle(nameOrPath string) error {
+// Bundle installs a bundle or local Brewfile, interactively in bbrew unless headless is set
+func Bundle(nameOrPath string, headless bool) error {
 	if _, err := exec.LookPath("brew"); err != nil {
 		return fmt.Errorf("Homebrew not found. Please install Homebrew first: https://brew.sh")
 	}
@@ -78,14 +80,50 @@ func Bundle(nameOrPath string) error {
 	}
 	defer cleanup()
 
-	if err := EnsureBbrew(); err != nil {
-		return err
+	return InstallBrewfile(brewfilePath, []BundleFile{{Name: BundleName(nameOrPath), Path: brewfilePath}}, headless)
+}
+
+// InstallBrewfile installs a (possibly merged or filtered) Brewfile with bbrew or brew bundle,
+// then its flatpaks, and records what got installed against the bundles in files
+func InstallBrewfile(brewfilePath string, files []BundleFile, headless bool) error {
+	if !headless {
+		if err := EnsureBbrew(); err != nil {
+			return err
+		}
+	}
+
+	before := TakeSnapshot()
+	// Record whatever got installed, even if bbrew or flatpak fails part way or the user
+	// interrupts the install
+	record := sync.OnceFunc(func() { RecordBundleInstalls(before, files) })
+	defer onInterrupt(record)()
+	defer record()
+
+	if headless {
+		if err := InstallHeadless(brewfilePath); err != nil {
+			return err
+		}
+	} else {
+		fmt.Println(infoStyle.Render(fmt.Sprintf("🍺 Opening %s in bbrew...", brewfilePath)))
+		if err := RunBbrew(brewfilePath); err != nil {
+			return fmt.Errorf("bbrew failed: %w", err)
+		}
 	}
 
-	fmt.Println(infoStyle.Render(fmt.Sprintf("🍺 Opening %s in bbrew...", brewfilePath)))
+	if IsLinux() {
+		if err := InstallBrewfileFlatpaks(brewfilePath, DefaultFlatpakScope()); err != nil {
+			return err
+		}
+	}
 
-	if err := RunBbrew(brewfilePath); err != nil {
-		return fmt.Errorf("bbrew failed: %w", err)
+	// Remember what each bundle contained, for `install diff`
+	for _, f := range files {
+		if f.Partial {
+			continue
+		}
+		if err := SaveBundleSnapshot(f.Name, f.Path); err != nil {
+			fmt.Println(errorStyle.Render(fmt.Sprintf("Warning: failed to save snapshot of %s: %v", f.Name, err)))
+		}
 	}
 
 	return nil
@@ -103,6 +141,9 @@ func GetBrewfile(nameOrPath string) (string, func(), error) {
 	if !ok {
 		return "", func() {}, fmt.Errorf("unknown bundle: %s (available: ai, cli, cncf, experimental-ide, fonts, full-desktop, ide, k8s, all)", nameOrPath)
 	}
+	if !BundleAllowed(nameOrPath) {
+		return "", func() {}, fmt.Errorf("bundle %s is not allowed by policy", nameOrPath)
+	}
 
 	if nameOrPath == "full-desktop" {
 		if err := EnsureFlathub(); err != nil {
@@ -110,23 +151,22 @@ func GetBrewfile(nameOrPath string) (string, func(), error) {
 		}
 	}
 
-	path := defaultBrewPath
-	if bundle.Path != "" {
-		path = bundle.Path
-	}
-
-	url := fmt.Sprintf("%s/%s/%s", commonBaseURL, path, bundle.File)
-	tmpDir := os.TempDir()
-	brewfilePath := filepath.Join(tmpDir, bundle.File)
-
-	fmt.Println(infoStyle.Render(fmt.Sprintf("⬇️  Downloading %s bundle...", nameOrPath)))
+	// Progress goes to stderr so commands with machine-readable output stay clean
+	fmt.Fprintln(os.Stderr, infoStyle.Render(fmt.Sprintf("⬇️  Downloading %s bundle...", nameOrPath)))
 
-	if err := downloadFile(url, brewfilePath); err != nil {
+	cachedPath, err := fetchBundle(bundle)
+	if err != nil {
 		return "", func() {}, fmt.Errorf("failed to download bundle: %w", err)
 	}
 
-	cleanup := func() {
-		os.Remove(brewfilePath)
+	tmpDir, cleanup, err := privateTempDir()
+	if err != nil {
+		return "", func() {}, err
+	}
+	brewfilePath := filepath.Join(tmpDir, bundle.File)
+	if err := copyFile(cachedPath, brewfilePath); err != nil {
+		cleanup()
+		return "", func() {}, err
 	}
 
 	return brewfilePath, cleanup, nil
@@ -137,33 +177,40 @@ func MergeBrewfiles(paths []string) (string, func(), error) {
 		return "", func() {}, fmt.Errorf("no brewfiles to merge")
 	}
 
-	tmpDir := os.TempDir()
+	tmpDir, cleanup, err := privateTempDir()
+	if err != nil {
+		return "", func() {}, err
+	}
 	mergedPath := filepath.Join(tmpDir, "merged.Brewfile")
 
-	f, err := os.Create(mergedPath)
-	if err != nil {
+	if err := writeMerged(mergedPath, paths); err != nil {
+		cleanup()
 		return "", func() {}, err
 	}
+
+	return mergedPath, cleanup, nil
+}
+
+func writeMerged(mergedPath string, paths []string) error {
+	f, err := createPrivateFile(mergedPath)
+	if err != nil {
+		return err
+	}
 	defer f.Close()
 
 	for _, p := range paths {
 		content, err := os.ReadFile(p)
 		if err != nil {
-			return "", func() {}, err
+			return err
 		}
 		if _, err := f.Write(content); err != nil {
-			return "", func() {}, err
+			return err
 		}
 		if _, err := f.WriteString("\n"); err != nil {
-			return "", func() {}, err
+			return err
 		}
 	}
-
-	cleanup := func() {
-		os.Remove(mergedPath)
-	}
-
-	return mergedPath, cleanup, nil
+	return f.Close()
 }
 
 func CheckBbrew() error {
@@ -194,11 +241,20 @@ func RunBbrew(brewfilePath string) error {
 	return cmd.Run()
 }
 
+// BundleAllowed reports whether the policy allows installing a built-in bundle
+func BundleAllowed(name string) bool {
+	pol, _ := policy.Load()
+	return pol.BundleAllowed(name)
+}
+
 func ListBundles() {
 	fmt.Println(titleStyle.Render("📦 Available Homebrew Bundles"))
 	fmt.Println()
 
 	for name, bundle := range bundles {
+		if !BundleAllowed(name) {
+			continue
+		}
 		fmt.Printf("  %s %s\n", 
 			lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Bold(true).Render(name+":"),
 			bundle.Description)
@@ -210,25 +266,22 @@ func ListBundles() {
 	fmt.Println("  bluefin-cli install /path/to/Brewfile")
 }
 
-func downloadFile(url, filepath string) error {
-	resp, err := http.Get(url)
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
+	defer in.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to download: HTTP %d", resp.StatusCode)
-	}
-
-	out, err := os.Create(filepath)
+	out, err := createPrivateFile(dst)
 	if err != nil {
 		return err
 	}
-	defer out.Close()
-
-	_, err = io.Copy(out, resp.Body)
-	return err
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
 }
 
 func IsLinux() bool {
@@ -246,18 +299,29 @@ func CheckFlatpak() error {
 }
 
 func EnsureFlathub() error {
+	return ensureFlathub("")
+}
+
+// ensureFlathub adds the Flathub remote to the given scope, or flatpak's default installation when empty
+func ensureFlathub(scope FlatpakScope) error {
 	if err := CheckFlatpak(); err != nil {
 		return fmt.Errorf("flatpak not found. Please install flatpak first: https://flatpak.org/setup/")
 	}
 
-	cmd := exec.Command("flatpak", "remote-list")
+	var scopeArgs []string
+	if scope != "" {
+		scopeArgs = []string{scope.flag()}
+	}
+
+	cmd := exec.Command("flatpak", append([]string{"remote-list"}, scopeArgs...)...)
 	out, err := cmd.Output()
 	if err == nil && strings.Contains(string(out), "flathub") {
 		return nil
 	}
 
 	fmt.Println(infoStyle.Render("Adding Flathub remote..."))
-	addCmd := exec.Command("flatpak", "remote-add", "--if-not-exists", "flathub", "https://dl.flathub.org/repo/flathub.flatpakrepo")
+	addArgs := append([]string{"remote-add", "--if-not-exists"}, scopeArgs...)
+	addCmd := exec.Command("flatpak", append(addArgs, "flathub", flathubURL)...)
 	addCmd.Stdout = os.Stdout
 	addCmd.Stderr = os.Stderr
 	return addCmd.Run()
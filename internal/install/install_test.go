@@ -1,8 +1,13 @@
 package install
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
 	"testing"
+	"time"
 )
 
 func TestListBundles(t *testing.T) {
@@ -74,46 +79,96 @@ brew "git"
 	t.Log("Skipping actual bundle installation in unit test")
 }
 
-func TestDownloadFile(t *testing.T) {
-	// Test with a known good URL
-	tmpFile, err := os.CreateTemp("", "download-test-*")
-	if err != nil {
-		t.Fatalf("Failed to create temp file: %v", err)
-	}
-	tmpPath := tmpFile.Name()
-	tmpFile.Close()
-	defer os.Remove(tmpPath)
+func TestMergeBrewfilesParallel(t *testing.T) {
+	const workers = 8
 
-	// Try to download a small file
-	url := "https://raw.githubusercontent.com/ublue-os/bluefin/main/README.md"
-	if err := downloadFile(url, tmpPath); err != nil {
-		t.Skipf("Skipping download test (network required): %v", err)
-	}
+	for i := 0; i < workers; i++ {
+		i := i
+		t.Run(fmt.Sprintf("merge-%d", i), func(t *testing.T) {
+			t.Parallel()
 
-	// Verify file was created and has content
-	info, err := os.Stat(tmpPath)
-	if err != nil {
-		t.Fatalf("Failed to stat downloaded file: %v", err)
+			dir := t.TempDir()
+			a := filepath.Join(dir, "a.Brewfile")
+			b := filepath.Join(dir, "b.Brewfile")
+			os.WriteFile(a, []byte(fmt.Sprintf("brew \"a-%d\"", i)), 0644)
+			os.WriteFile(b, []byte(fmt.Sprintf("brew \"b-%d\"", i)), 0644)
+
+			merged, cleanup, err := MergeBrewfiles([]string{a, b})
+			if err != nil {
+				t.Fatalf("MergeBrewfiles() failed: %v", err)
+			}
+
+			info, err := os.Stat(merged)
+			if err != nil {
+				t.Fatalf("Merged Brewfile missing: %v", err)
+			}
+			if runtime.GOOS != "windows" {
+				if perm := info.Mode().Perm(); perm != 0600 {
+					t.Errorf("Expected merged Brewfile mode 0600, got %o", perm)
+				}
+				if dirInfo, err := os.Stat(filepath.Dir(merged)); err != nil || dirInfo.Mode().Perm() != 0700 {
+					t.Errorf("Expected a private temp directory, got %v (%v)", dirInfo.Mode(), err)
+				}
+			}
+
+			// Give the other merges a chance to clobber this one if they shared a path
+			time.Sleep(10 * time.Millisecond)
+
+			content, err := os.ReadFile(merged)
+			if err != nil {
+				t.Fatalf("Failed to read merged Brewfile: %v", err)
+			}
+			want := fmt.Sprintf("brew \"a-%d\"\nbrew \"b-%d\"\n", i, i)
+			if string(content) != want {
+				t.Errorf("Merged Brewfile = %q, want %q", content, want)
+			}
+
+			cleanup()
+			if _, err := os.Stat(filepath.Dir(merged)); !os.IsNotExist(err) {
+				t.Errorf("Expected cleanup to remove %s", filepath.Dir(merged))
+			}
+		})
 	}
+}
 
-	if info.Size() == 0 {
-		t.Error("Downloaded file is empty")
+func TestCreatePrivateFileRefusesExisting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "merged.Brewfile")
+	if err := os.Symlink(filepath.Join(t.TempDir(), "target"), path); err != nil {
+		t.Skipf("Symlinks unavailable: %v", err)
+	}
+	if _, err := createPrivateFile(path); err == nil {
+		t.Error("Expected createPrivateFile to refuse a planted symlink")
 	}
 }
 
-func TestDownloadFileInvalidURL(t *testing.T) {
-	tmpFile, err := os.CreateTemp("", "download-test-*")
+func TestHandleInterrupt(t *testing.T) {
+	origExit := exit
+	defer func() { exit = origExit }()
+	code := -1
+	exit = func(c int) { code = c }
+
+	dir, cleanup, err := privateTempDir()
 	if err != nil {
-		t.Fatalf("Failed to create temp file: %v", err)
+		t.Fatal(err)
 	}
-	tmpPath := tmpFile.Name()
-	tmpFile.Close()
-	defer os.Remove(tmpPath)
+	defer cleanup()
+
+	recorded := 0
+	record := func() { recorded++ }
+	remove := onInterrupt(record)
+	removed := onInterrupt(func() { t.Error("Expected an unregistered hook not to run") })
+	removed()
 
-	// Try to download from invalid URL
-	url := "https://invalid.invalid/nonexistent.txt"
-	err = downloadFile(url, tmpPath)
-	if err == nil {
-		t.Error("Expected error for invalid URL, got nil")
+	handleInterrupt(syscall.SIGTERM)
+	remove()
+
+	if recorded != 1 {
+		t.Errorf("Expected the install to be recorded before exiting, got %d", recorded)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Expected the temporary directory to be removed, got %v", err)
+	}
+	if code != 143 {
+		t.Errorf("Expected exit status 143, got %d", code)
 	}
 }
@@ -0,0 +1,165 @@
+package install
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// PackageInfo describes a Brewfile package for the bundle preview
+type PackageInfo struct {
+	Entry     BrewfileEntry
+	Desc      string
+	Installed bool
+}
+
+// IsPackage reports whether an entry installs something, as opposed to e.g. a tap
+func (e BrewfileEntry) IsPackage() bool {
+	switch e.Kind {
+	case "brew", "cask", "flatpak", "mas", "vscode":
+		return true
+	}
+	return false
+}
+
+// DescribeEntries returns the packages of a Brewfile once each, with descriptions from
+// `brew info` and whether they are installed already
+func DescribeEntries(entries []BrewfileEntry) []PackageInfo {
+	installed := TakeSnapshot()
+
+	var names []string
+	var packages []PackageInfo
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		if !e.IsPackage() || seen[e.Kind+":"+e.Name] {
+			continue
+		}
+		seen[e.Kind+":"+e.Name] = true
+
+		info := PackageInfo{Entry: e}
+		short := e.Name[strings.LastIndex(e.Name, "/")+1:]
+		switch e.Kind {
+		case "brew", "cask":
+			names = append(names, e.Name)
+			_, info.Installed = installed[snapshotKey("brew", short)]
+		case "flatpak":
+			_, user := installed[snapshotKey(Flatpak{Scope: FlatpakUser}.Name(), e.Name)]
+			_, system := installed[snapshotKey(Flatpak{Scope: FlatpakSystem}.Name(), e.Name)]
+			info.Installed = user || system
+		}
+		packages = append(packages, info)
+	}
+
+	if len(names) > 0 {
+		// brew info fails as a whole if one name is unknown, so descriptions are best effort
+		if out, err := exec.Command("brew", append([]string{"info", "--json=v2"}, names...)...).Output(); err == nil {
+			if descs, err := parseBrewInfo(out); err == nil {
+				for i := range packages {
+					name := packages[i].Entry.Name
+					if desc, ok := descs[name]; ok {
+						packages[i].Desc = desc
+					} else {
+						packages[i].Desc = descs[name[strings.LastIndex(name, "/")+1:]]
+					}
+				}
+			}
+		}
+	}
+
+	return packages
+}
+
+// parseBrewInfo maps formula and cask names (short and tap-qualified) to their description
+func parseBrewInfo(data []byte) (map[string]string, error) {
+	var info struct {
+		Formulae []struct {
+			Name     string `json:"name"`
+			FullName string `json:"full_name"`
+			Desc     string `json:"desc"`
+		} `json:"formulae"`
+		Casks []struct {
+			Token     string `json:"token"`
+			FullToken string `json:"full_token"`
+			Desc      string `json:"desc"`
+		} `json:"casks"`
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse brew info output: %w", err)
+	}
+
+	descs := make(map[string]string)
+	for _, f := range info.Formulae {
+		descs[f.Name] = f.Desc
+		descs[f.FullName] = f.Desc
+	}
+	for _, c := range info.Casks {
+		descs[c.Token] = c.Desc
+		descs[c.FullToken] = c.Desc
+	}
+	return descs, nil
+}
+
+// FilterBrewfile writes the entries of a Brewfile for which keep returns true to a private
+// temporary Brewfile. Taps and other non-package entries are always kept.
+func FilterBrewfile(path string, keep func(BrewfileEntry) bool) (string, func(), error) {
+	entries, err := ParseBrewfile(path)
+	if err != nil {
+		return "", func() {}, err
+	}
+
+	var filtered []BrewfileEntry
+	for _, e := range entries {
+		if !e.IsPackage() || keep(e) {
+			filtered = append(filtered, e)
+		}
+	}
+
+	tmpDir, cleanup, err := privateTempDir()
+	if err != nil {
+		return "", func() {}, err
+	}
+	filteredPath := filepath.Join(tmpDir, filepath.Base(path))
+	if err := writeBrewfile(filteredPath, filtered); err != nil {
+		cleanup()
+		return "", func() {}, err
+	}
+	return filteredPath, cleanup, nil
+}
+
+func writeBrewfile(path string, entries []BrewfileEntry) error {
+	f, err := createPrivateFile(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	for _, e := range entries {
+		if _, err := fmt.Fprintln(f, e.String()); err != nil {
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// InstallHeadless installs a Brewfile with `brew bundle` instead of bbrew's interactive UI.
+// Flatpak entries are left to InstallBrewfileFlatpaks.
+func InstallHeadless(brewfilePath string) error {
+	brewOnly, cleanup, err := FilterBrewfile(brewfilePath, func(e BrewfileEntry) bool { return e.Kind != "flatpak" })
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	fmt.Println(infoStyle.Render("🍺 Installing with brew bundle..."))
+	cmd := exec.Command("brew", "bundle", "install", "--no-upgrade", "--file", brewOnly)
+	cmd.Env = append(os.Environ(), "HOMEBREW_NO_ENV_HINTS=1")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("brew bundle failed: %w", err)
+	}
+	return nil
+}
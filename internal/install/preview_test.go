@@ -0,0 +1,85 @@
+package install
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const fakeBrewScript = `#!/bin/sh
+case "$1" in
+info)
+	echo '{"formulae":[{"name":"k9s","full_name":"derailed/k9s/k9s","desc":"Kubernetes CLI"},{"name":"kubectl","full_name":"kubectl","desc":"Kubernetes command-line interface"}],"casks":[{"token":"lens","full_token":"lens","desc":"Kubernetes IDE"}]}'
+	;;
+list)
+	[ "$2" = "--formula" ] && echo "kubectl 1.31.0"
+	;;
+esac
+`
+
+func TestDescribeEntries(t *testing.T) {
+	binDir := t.TempDir()
+	os.WriteFile(filepath.Join(binDir, "brew"), []byte(fakeBrewScript), 0755)
+	t.Setenv("PATH", binDir)
+
+	entries := []BrewfileEntry{
+		{Kind: "tap", Name: "derailed/k9s"},
+		{Kind: "brew", Name: "derailed/k9s/k9s"},
+		{Kind: "brew", Name: "kubectl"},
+		{Kind: "brew", Name: "kubectl"},
+		{Kind: "cask", Name: "lens"},
+	}
+
+	packages := DescribeEntries(entries)
+	if len(packages) != 3 {
+		t.Fatalf("Expected 3 unique packages, got %+v", packages)
+	}
+	if packages[0].Desc != "Kubernetes CLI" || packages[0].Installed {
+		t.Errorf("Unexpected k9s info: %+v", packages[0])
+	}
+	if packages[1].Desc != "Kubernetes command-line interface" || !packages[1].Installed {
+		t.Errorf("Unexpected kubectl info: %+v", packages[1])
+	}
+	if packages[2].Desc != "Kubernetes IDE" {
+		t.Errorf("Unexpected lens info: %+v", packages[2])
+	}
+}
+
+func TestFilterBrewfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "merged.Brewfile")
+	os.WriteFile(path, []byte(`tap "derailed/k9s"
+brew "derailed/k9s/k9s"
+brew "kubectl", args: ["HEAD"]
+cask "lens"
+flatpak "org.gnome.Boxes"
+`), 0644)
+
+	keep := map[string]bool{"kubectl": true, "org.gnome.Boxes": true}
+	filtered, cleanup, err := FilterBrewfile(path, func(e BrewfileEntry) bool { return keep[e.Name] })
+	if err != nil {
+		t.Fatalf("FilterBrewfile() failed: %v", err)
+	}
+	defer cleanup()
+
+	content, err := os.ReadFile(filtered)
+	if err != nil {
+		t.Fatalf("Failed to read filtered Brewfile: %v", err)
+	}
+	want := `tap "derailed/k9s"
+brew "kubectl", args: ["HEAD"]
+flatpak "org.gnome.Boxes"
+`
+	if string(content) != want {
+		t.Errorf("Filtered Brewfile =\n%s\nwant\n%s", content, want)
+	}
+	if !strings.HasPrefix(filtered, os.TempDir()) {
+		t.Errorf("Expected the filtered Brewfile in a temp dir, got %s", filtered)
+	}
+}
+
+func TestParseBrewInfoInvalid(t *testing.T) {
+	if _, err := parseBrewInfo([]byte("Error: No available formula")); err == nil {
+		t.Error("Expected an error for invalid brew info output")
+	}
+}
@@ -0,0 +1,125 @@
+package install
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/hanthor/bluefin-cli/internal/ledger"
+)
+
+// Snapshot maps installed packages, keyed by backend and package name, to their version
+type Snapshot map[string]string
+
+// BundleFile pairs a bundle name with its downloaded Brewfile
+type BundleFile struct {
+	Name string
+	Path string
+	// Partial files list only some of the bundle's entries, so they don't replace its snapshot
+	Partial bool
+}
+
+func snapshotKey(backend, pkg string) string {
+	return backend + ":" + pkg
+}
+
+// TakeSnapshot lists the installed Homebrew formulae and casks and flatpak applications
+func TakeSnapshot() Snapshot {
+	snap := make(Snapshot)
+	for _, kind := range []string{"--formula", "--cask"} {
+		out, err := exec.Command("brew", "list", kind, "--versions").Output()
+		if err != nil {
+			continue
+		}
+		for name, version := range parseBrewVersions(string(out)) {
+			snap[snapshotKey("brew", name)] = version
+		}
+	}
+
+	if apps, err := ListFlatpaks(); err == nil {
+		for _, app := range apps {
+			snap[snapshotKey(Flatpak{Scope: app.Scope}.Name(), app.ID)] = app.Version
+		}
+	}
+	return snap
+}
+
+// parseBrewVersions parses `brew list --versions` lines of "<name> <version> [<version>...]",
+// keeping the newest (last) version
+func parseBrewVersions(out string) map[string]string {
+	versions := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		versions[fields[0]] = fields[len(fields)-1]
+		if len(fields) == 1 {
+			versions[fields[0]] = ""
+		}
+	}
+	return versions
+}
+
+// BundleName is the ledger origin of a bundle argument: the bundle name, or the file name of a local Brewfile
+func BundleName(nameOrPath string) string {
+	if strings.Contains(nameOrPath, "/") || strings.Contains(nameOrPath, "\\") {
+		return filepath.Base(nameOrPath)
+	}
+	return nameOrPath
+}
+
+// RecordBundleInstalls records the Brewfile entries that were installed since before was taken.
+// A package listed in several bundles is attributed to the first one.
+func RecordBundleInstalls(before Snapshot, files []BundleFile) error {
+	return ledger.Record(bundleInstalls(before, TakeSnapshot(), files)...)
+}
+
+func bundleInstalls(before, after Snapshot, files []BundleFile) []ledger.Entry {
+	var entries []ledger.Entry
+	seen := make(map[string]bool)
+
+	for _, file := range files {
+		brewfile, err := ParseBrewfile(file.Path)
+		if err != nil {
+			continue
+		}
+
+		for _, e := range brewfile {
+			var backends []string
+			kind := e.Kind
+			name := e.Name
+			switch e.Kind {
+			case "brew", "cask":
+				if e.Kind == "brew" {
+					kind = "formula"
+				}
+				// Tapped packages are listed by their short name
+				name = name[strings.LastIndex(name, "/")+1:]
+				backends = []string{"brew"}
+			case "flatpak":
+				backends = []string{Flatpak{Scope: FlatpakUser}.Name(), Flatpak{Scope: FlatpakSystem}.Name()}
+			default:
+				continue
+			}
+
+			for _, backend := range backends {
+				key := snapshotKey(backend, name)
+				version, installed := after[key]
+				if _, existed := before[key]; !installed || existed || seen[key] {
+					continue
+				}
+				seen[key] = true
+				entries = append(entries, ledger.Entry{
+					Action:  ledger.ActionInstall,
+					Package: name,
+					Backend: backend,
+					Version: version,
+					Kind:    kind,
+					Bundle:  file.Name,
+				})
+			}
+		}
+	}
+	return entries
+}
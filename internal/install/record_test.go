@@ -0,0 +1,67 @@
+package install
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBundleInstalls(t *testing.T) {
+	dir := t.TempDir()
+	k8s := filepath.Join(dir, "k8s.Brewfile")
+	desktop := filepath.Join(dir, "desktop.Brewfile")
+	os.WriteFile(k8s, []byte("tap \"derailed/k9s\"\nbrew \"derailed/k9s/k9s\"\nbrew \"kubectl\"\nbrew \"helm\"\n"), 0644)
+	os.WriteFile(desktop, []byte("brew \"helm\"\ncask \"visual-studio-code\"\nflatpak \"org.gnome.Boxes\"\n"), 0644)
+
+	before := Snapshot{"brew:kubectl": "1.31.0"}
+	after := Snapshot{
+		"brew:kubectl":                   "1.31.0",
+		"brew:k9s":                       "0.32.5",
+		"brew:helm":                      "3.16.1",
+		"brew:visual-studio-code":        "1.95.0",
+		"flatpak-system:org.gnome.Boxes": "46.1",
+	}
+
+	entries := bundleInstalls(before, after, []BundleFile{{Name: "k8s", Path: k8s}, {Name: "desktop", Path: desktop}})
+
+	got := make(map[string]string)
+	for _, e := range entries {
+		got[e.Backend+":"+e.Package+":"+e.Kind] = e.Bundle
+		if e.Version != after[e.Backend+":"+e.Package] {
+			t.Errorf("Expected %s to record version %q, got %q", e.Package, after[e.Backend+":"+e.Package], e.Version)
+		}
+	}
+	want := map[string]string{
+		"brew:k9s:formula":                       "k8s",
+		"brew:helm:formula":                      "k8s",
+		"brew:visual-studio-code:cask":           "desktop",
+		"flatpak-system:org.gnome.Boxes:flatpak": "desktop",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d entries, got %+v", len(want), entries)
+	}
+	for k, bundle := range want {
+		if got[k] != bundle {
+			t.Errorf("Expected %s attributed to %q, got %q", k, bundle, got[k])
+		}
+	}
+}
+
+func TestBundleName(t *testing.T) {
+	if got := BundleName("k8s"); got != "k8s" {
+		t.Errorf("BundleName(k8s) = %q", got)
+	}
+	if got := BundleName("/tmp/my.Brewfile"); got != "my.Brewfile" {
+		t.Errorf("BundleName(path) = %q", got)
+	}
+}
+
+func TestParseBrewVersions(t *testing.T) {
+	versions := parseBrewVersions("k9s 0.32.5\nopenssl@3 3.3.1 3.3.2\n\nbroken\n")
+	if versions["k9s"] != "0.32.5" || versions["openssl@3"] != "3.3.2" {
+		t.Errorf("Unexpected versions: %v", versions)
+	}
+	if v, ok := versions["broken"]; !ok || v != "" {
+		t.Errorf("Expected a versionless entry for broken, got %q (%v)", v, ok)
+	}
+}
@@ -0,0 +1,186 @@
+package install
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hanthor/bluefin-cli/internal/env"
+)
+
+// BundleSnapshot is the parsed content of a bundle's Brewfile when it was last installed
+type BundleSnapshot struct {
+	Bundle  string          `json:"bundle"`
+	Time    time.Time       `json:"time"`
+	Entries []BrewfileEntry `json:"entries"`
+}
+
+// EntryChange is an entry whose options differ between two versions of a Brewfile
+type EntryChange struct {
+	Old BrewfileEntry `json:"old"`
+	New BrewfileEntry `json:"new"`
+}
+
+// BundleDiff lists what changed in a Brewfile since a snapshot
+type BundleDiff struct {
+	Added   []BrewfileEntry `json:"added"`
+	Removed []BrewfileEntry `json:"removed"`
+	Changed []EntryChange   `json:"changed"`
+
+	// Current is the Brewfile DiffBundle compared, which InstallAdditions installs from so
+	// the packages installed are the ones that were reviewed
+	Current []BrewfileEntry `json:"-"`
+}
+
+// Empty reports whether nothing changed
+func (d BundleDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+func snapshotPath(bundle string) (string, error) {
+	dir, err := env.GetStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "bundles", bundle+".json"), nil
+}
+
+// SaveBundleSnapshot stores the parsed entries of a bundle's Brewfile
+func SaveBundleSnapshot(bundle, brewfilePath string) error {
+	entries, err := ParseBrewfile(brewfilePath)
+	if err != nil {
+		return err
+	}
+
+	path, err := snapshotPath(bundle)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(BundleSnapshot{Bundle: bundle, Time: time.Now(), Entries: entries}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write bundle snapshot: %w", err)
+	}
+	return nil
+}
+
+// LoadBundleSnapshot returns the snapshot taken when the bundle was last installed
+func LoadBundleSnapshot(bundle string) (*BundleSnapshot, error) {
+	path, err := snapshotPath(bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no snapshot of %s yet; one is taken when the bundle is installed", bundle)
+		}
+		return nil, err
+	}
+
+	var snap BundleSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle snapshot: %w", err)
+	}
+	return &snap, nil
+}
+
+// DiffEntries compares two versions of a Brewfile by kind and name, keeping the order of each
+func DiffEntries(old, current []BrewfileEntry) BundleDiff {
+	key := func(e BrewfileEntry) string { return e.Kind + ":" + e.Name }
+
+	before := make(map[string]BrewfileEntry)
+	for _, e := range old {
+		before[key(e)] = e
+	}
+	after := make(map[string]bool)
+
+	var diff BundleDiff
+	for _, e := range current {
+		after[key(e)] = true
+		prev, ok := before[key(e)]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, e)
+		case prev.Options != e.Options:
+			diff.Changed = append(diff.Changed, EntryChange{Old: prev, New: e})
+		}
+	}
+	for _, e := range old {
+		if !after[key(e)] {
+			diff.Removed = append(diff.Removed, e)
+		}
+	}
+	return diff
+}
+
+// DiffBundle fetches the current Brewfile of a bundle and compares it with the last installed snapshot
+func DiffBundle(nameOrPath string) (*BundleSnapshot, BundleDiff, error) {
+	snap, err := LoadBundleSnapshot(BundleName(nameOrPath))
+	if err != nil {
+		return nil, BundleDiff{}, err
+	}
+
+	brewfilePath, cleanup, err := GetBrewfile(nameOrPath)
+	if err != nil {
+		return nil, BundleDiff{}, err
+	}
+	defer cleanup()
+
+	current, err := ParseBrewfile(brewfilePath)
+	if err != nil {
+		return nil, BundleDiff{}, err
+	}
+	diff := DiffEntries(snap.Entries, current)
+	diff.Current = current
+	return snap, diff, nil
+}
+
+// InstallAdditions installs only the entries a diff of the bundle reports as added, from
+// the Brewfile the diff was computed against rather than downloading it again
+func InstallAdditions(nameOrPath string, diff BundleDiff, headless bool) error {
+	if len(diff.Added) == 0 {
+		fmt.Println(infoStyle.Render("Nothing was added to the bundle"))
+		return nil
+	}
+	if diff.Current == nil {
+		return fmt.Errorf("the diff of %s has no Brewfile to install from", nameOrPath)
+	}
+
+	tmpDir, cleanup, err := privateTempDir()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	added := make(map[string]bool)
+	for _, e := range diff.Added {
+		added[e.Kind+":"+e.Name] = true
+	}
+	var additions []BrewfileEntry
+	for _, e := range diff.Current {
+		if !e.IsPackage() || added[e.Kind+":"+e.Name] {
+			additions = append(additions, e)
+		}
+	}
+
+	brewfilePath := filepath.Join(tmpDir, "bundle.Brewfile")
+	additionsPath := filepath.Join(tmpDir, "additions.Brewfile")
+	if err := writeBrewfile(brewfilePath, diff.Current); err != nil {
+		return err
+	}
+	if err := writeBrewfile(additionsPath, additions); err != nil {
+		return err
+	}
+
+	return InstallBrewfile(additionsPath, []BundleFile{{Name: BundleName(nameOrPath), Path: brewfilePath}}, headless)
+}
@@ -0,0 +1,90 @@
+package install
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiffEntries(t *testing.T) {
+	old := []BrewfileEntry{
+		{Kind: "tap", Name: "derailed/k9s"},
+		{Kind: "brew", Name: "kubectl"},
+		{Kind: "brew", Name: "helm"},
+		{Kind: "cask", Name: "lens", Options: `greedy: true`},
+	}
+	current := []BrewfileEntry{
+		{Kind: "tap", Name: "derailed/k9s"},
+		{Kind: "brew", Name: "kubectl"},
+		{Kind: "cask", Name: "lens"},
+		{Kind: "brew", Name: "kind"},
+		{Kind: "flatpak", Name: "org.gnome.Boxes"},
+	}
+
+	diff := DiffEntries(old, current)
+	if len(diff.Added) != 2 || diff.Added[0].Name != "kind" || diff.Added[1].Name != "org.gnome.Boxes" {
+		t.Errorf("Unexpected additions: %+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Name != "helm" {
+		t.Errorf("Unexpected removals: %+v", diff.Removed)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].Old.Options != "greedy: true" || diff.Changed[0].New.Options != "" {
+		t.Errorf("Unexpected changes: %+v", diff.Changed)
+	}
+
+	if !DiffEntries(current, current).Empty() {
+		t.Error("Expected no changes between identical Brewfiles")
+	}
+}
+
+func TestBundleSnapshotRoundTrip(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	if _, err := LoadBundleSnapshot("k8s"); err == nil {
+		t.Error("Expected an error before any snapshot was taken")
+	}
+
+	brewfile := filepath.Join(t.TempDir(), "k8s.Brewfile")
+	if err := os.WriteFile(brewfile, []byte("tap \"derailed/k9s\"\nbrew \"kubectl\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveBundleSnapshot("k8s", brewfile); err != nil {
+		t.Fatalf("SaveBundleSnapshot failed: %v", err)
+	}
+
+	snap, err := LoadBundleSnapshot("k8s")
+	if err != nil {
+		t.Fatalf("LoadBundleSnapshot failed: %v", err)
+	}
+	if snap.Bundle != "k8s" || snap.Time.IsZero() || len(snap.Entries) != 2 || snap.Entries[1].Name != "kubectl" {
+		t.Errorf("Unexpected snapshot: %+v", snap)
+	}
+}
+
+func TestDiffBundleKeepsBrewfile(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	brewfile := filepath.Join(t.TempDir(), "team.Brewfile")
+	if err := os.WriteFile(brewfile, []byte("brew \"kubectl\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveBundleSnapshot(BundleName(brewfile), brewfile); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(brewfile, []byte("brew \"kubectl\"\nbrew \"k9s\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, diff, err := DiffBundle(brewfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Added) != 1 || len(diff.Current) != 2 || diff.Current[1].Name != "k9s" {
+		t.Errorf("Expected the compared Brewfile with the diff, got %+v", diff)
+	}
+
+	// A diff without the Brewfile it was computed from can't be installed
+	if err := InstallAdditions(brewfile, BundleDiff{Added: diff.Added}, true); err == nil {
+		t.Error("Expected an error for a diff without its Brewfile")
+	}
+}
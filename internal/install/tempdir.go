@@ -0,0 +1,129 @@
+package install
+
+import (
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+)
+
+var (
+	tempMu     sync.Mutex
+	tempDirs   = make(map[string]bool)
+	signalOnce sync.Once
+
+	hooksMu        sync.Mutex
+	interruptHooks = make(map[int]func())
+	nextHook       int
+
+	// exit ends the process after an interrupt, replaced in tests
+	exit = os.Exit
+)
+
+// privateTempDir creates a directory only the current user can access (0700). It is removed
+// by the returned cleanup, or when the process is interrupted or terminated.
+func privateTempDir() (string, func(), error) {
+	dir, err := os.MkdirTemp("", "bluefin-cli-*")
+	if err != nil {
+		return "", func() {}, err
+	}
+
+	signalOnce.Do(removeTempDirsOnSignal)
+
+	tempMu.Lock()
+	tempDirs[dir] = true
+	tempMu.Unlock()
+
+	cleanup := func() {
+		tempMu.Lock()
+		delete(tempDirs, dir)
+		tempMu.Unlock()
+		os.RemoveAll(dir)
+	}
+	return dir, cleanup, nil
+}
+
+// createPrivateFile creates a new file readable and writable only by the current user.
+// It fails if the file already exists rather than following a planted link.
+func createPrivateFile(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+}
+
+// onInterrupt registers fn to run before the process exits on SIGINT or SIGTERM, e.g. to
+// record what an interrupted install got done. The returned func unregisters it. fn may
+// also run concurrently with the caller's own use of it, so it should be guarded, e.g. by
+// sync.OnceFunc.
+func onInterrupt(fn func()) func() {
+	signalOnce.Do(removeTempDirsOnSignal)
+
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	id := nextHook
+	nextHook++
+	interruptHooks[id] = fn
+	return func() {
+		hooksMu.Lock()
+		delete(interruptHooks, id)
+		hooksMu.Unlock()
+	}
+}
+
+func removeTempDirsOnSignal() {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		handleInterrupt(<-sigs)
+	}()
+}
+
+// handleInterrupt runs the interrupt hooks, removes the temporary directories and exits
+// with the conventional status for sig
+func handleInterrupt(sig os.Signal) {
+	hooksMu.Lock()
+	hooks := make([]func(), 0, len(interruptHooks))
+	for _, fn := range interruptHooks {
+		hooks = append(hooks, fn)
+	}
+	hooksMu.Unlock()
+	for _, fn := range hooks {
+		fn()
+	}
+
+	tempMu.Lock()
+	for dir := range tempDirs {
+		os.RemoveAll(dir)
+	}
+	tempMu.Unlock()
+
+	code := 130 // 128 + SIGINT
+	if sig == syscall.SIGTERM {
+		code = 143
+	}
+	exit(code)
+}
+
+// WriteTempBrewfile writes content to a Brewfile in a private temporary directory
+func WriteTempBrewfile(name, content string) (string, func(), error) {
+	dir, cleanup, err := privateTempDir()
+	if err != nil {
+		return "", func() {}, err
+	}
+
+	path := filepath.Join(dir, name)
+	f, err := createPrivateFile(path)
+	if err != nil {
+		cleanup()
+		return "", func() {}, err
+	}
+	_, err = f.WriteString(content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return "", func() {}, err
+	}
+	return path, cleanup, nil
+}
@@ -2,14 +2,41 @@ package install
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"sort"
 	"strings"
+
+	"github.com/hanthor/bluefin-cli/internal/config"
+	"github.com/hanthor/bluefin-cli/internal/ledger"
+)
+
+// CaskInfo is the metadata of a cask parsed from its Ruby definition
+type CaskInfo struct {
+	Token     string
+	Tap       string
+	Name      string
+	Desc      string
+	Version   string
+	Homepage  string
+	Artifacts []string // artifact targets and other install locations, with ~ expanded
+	Installed bool
+	Size      int64 // Bytes on disk, only known when installed
+}
+
+var (
+	caskNameRe     = regexp.MustCompile(`(?m)^\s*name\s+"([^"]+)"`)
+	caskDescRe     = regexp.MustCompile(`(?m)^\s*desc\s+"([^"]+)"`)
+	caskVersionRe  = regexp.MustCompile(`(?m)^\s*version\s+(?:"([^"]+)"|:(\w+))`)
+	caskHomepageRe = regexp.MustCompile(`(?m)^\s*homepage\s+"([^"]+)"`)
+	caskHomePathRe = regexp.MustCompile(`"((?:#\{Dir\.home\}|~)/[^"]+)"`)
 )
 
-const wallpapersTap = "ublue-os/tap"
+var wallpaperLocations = []string{"/.local/share/backgrounds", "/.local/share/wallpapers", "/Library/Desktop Pictures"}
 
 func EnsureBrew() error {
 	if _, err := exec.LookPath("brew"); err != nil {
@@ -28,55 +55,187 @@ func ensureTap(tap string) error {
 	return cmd.Run()
 }
 
-func GetWallpaperCasks() ([]string, error) {
-	if err := ensureTap(wallpapersTap); err != nil {
-		return nil, err
-	}
+// WallpaperTaps returns the taps configured in config.json
+func WallpaperTaps() []string {
+	settings, _ := config.Load()
+	return settings.WallpaperTaps
+}
 
-	cmd := exec.Command("brew", "--repository", wallpapersTap)
-	out, err := cmd.Output()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get tap repository path: %w", err)
+// GetWallpaperCasks discovers wallpaper casks in the configured taps, sorted by token
+func GetWallpaperCasks() ([]CaskInfo, error) {
+	installed := installedCasks()
+
+	var casks []CaskInfo
+	for _, tap := range WallpaperTaps() {
+		if err := ensureTap(tap); err != nil {
+			return nil, err
+		}
+
+		out, err := exec.Command("brew", "--repository", tap).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tap repository path: %w", err)
+		}
+
+		tapCasks, err := readTapCasks(tap, filepath.Join(strings.TrimSpace(string(out)), "Casks"))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range tapCasks {
+			if !isWallpaperCask(c) {
+				continue
+			}
+			_, c.Installed = installed[c.Token]
+			if c.Installed {
+				c.Size = installedSize(c)
+			}
+			casks = append(casks, c)
+		}
 	}
 
-	tapPath := strings.TrimSpace(string(out))
-	casksDir := filepath.Join(tapPath, "Casks")
+	sort.Slice(casks, func(i, j int) bool { return casks[i].Token < casks[j].Token })
+	return casks, nil
+}
 
-	entries, err := os.ReadDir(casksDir)
+// readTapCasks parses every cask definition in a tap's Casks directory (flat or sharded)
+func readTapCasks(tap, casksDir string) ([]CaskInfo, error) {
+	var casks []CaskInfo
+
+	err := filepath.WalkDir(casksDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".rb") {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		c := ParseCask(string(content))
+		c.Token = strings.TrimSuffix(d.Name(), ".rb")
+		c.Tap = tap
+		casks = append(casks, c)
+		return nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to read casks directory at %s: %w", casksDir, err)
 	}
 
-	var casks []string
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
+	return casks, nil
+}
+
+// ParseCask extracts metadata from a cask's Ruby source. It does not evaluate Ruby,
+// so interpolated values are kept as written.
+func ParseCask(source string) CaskInfo {
+	var c CaskInfo
+	if m := caskNameRe.FindStringSubmatch(source); m != nil {
+		c.Name = m[1]
+	}
+	if m := caskDescRe.FindStringSubmatch(source); m != nil {
+		c.Desc = m[1]
+	}
+	if m := caskVersionRe.FindStringSubmatch(source); m != nil {
+		c.Version = m[1] + m[2]
+	}
+	if m := caskHomepageRe.FindStringSubmatch(source); m != nil {
+		c.Homepage = m[1]
+	}
+
+	home, _ := os.UserHomeDir()
+	seen := make(map[string]bool)
+	for _, m := range caskHomePathRe.FindAllStringSubmatch(source, -1) {
+		path := strings.TrimPrefix(strings.TrimPrefix(m[1], "#{Dir.home}"), "~")
+		// Keep only the static prefix of paths built from further interpolation
+		if i := strings.Index(path, "#{"); i >= 0 {
+			path = filepath.Dir(path[:i] + "x")
+		}
+		path = filepath.Join(home, path)
+		if !seen[path] {
+			seen[path] = true
+			c.Artifacts = append(c.Artifacts, path)
 		}
-		name := entry.Name()
-		if strings.HasSuffix(name, ".rb") {
-			caskName := strings.TrimSuffix(name, ".rb")
-			if strings.Contains(strings.ToLower(caskName), "wallpaper") {
-				casks = append(casks, caskName)
+	}
+
+	return c
+}
+
+// isWallpaperCask matches casks by name and description, or by where they install to
+func isWallpaperCask(c CaskInfo) bool {
+	text := strings.ToLower(c.Token + " " + c.Name + " " + c.Desc)
+	if strings.Contains(text, "wallpaper") || strings.Contains(text, "background") {
+		return true
+	}
+	for _, a := range c.Artifacts {
+		for _, loc := range wallpaperLocations {
+			if strings.Contains(a, loc) {
+				return true
 			}
 		}
 	}
+	return false
+}
 
-	return casks, nil
+// installedCasks maps installed cask tokens to their version
+func installedCasks() map[string]string {
+	out, err := exec.Command("brew", "list", "--cask", "--versions").Output()
+	if err != nil {
+		return make(map[string]string)
+	}
+	return parseBrewVersions(string(out))
 }
 
-func InstallWallpaperCasks(casks []string) error {
-	if err := ensureTap(wallpapersTap); err != nil {
-		return err
+// installedSize sums the files the cask placed on disk
+func installedSize(c CaskInfo) int64 {
+	var total int64
+	for _, path := range c.Artifacts {
+		filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+			return nil
+		})
 	}
+	return total
+}
+
+// InstallWallpaperCasks installs casks by token; tokens without a tap prefix are
+// resolved against the configured wallpaper taps
+func InstallWallpaperCasks(casks []string) error {
 	if len(casks) == 0 {
 		return fmt.Errorf("no wallpaper casks selected")
 	}
+
+	taps := WallpaperTaps()
+	if len(taps) == 0 {
+		return fmt.Errorf("no wallpaper taps configured")
+	}
+	for _, tap := range taps {
+		if err := ensureTap(tap); err != nil {
+			return err
+		}
+	}
+
+	tapOf := make(map[string]string)
+	if available, err := GetWallpaperCasks(); err == nil {
+		for _, c := range available {
+			tapOf[c.Token] = c.Tap
+		}
+	}
+
+	before := installedCasks()
+
 	args := []string{"install", "--cask"}
 	for _, c := range casks {
 		if strings.Contains(c, "/") {
 			args = append(args, c)
+		} else if tap, ok := tapOf[c]; ok {
+			args = append(args, tap+"/"+c)
 		} else {
-			args = append(args, wallpapersTap+"/"+c)
+			args = append(args, taps[0]+"/"+c)
 		}
 	}
 	cmd := exec.Command("brew", args...)
@@ -88,12 +247,39 @@ func InstallWallpaperCasks(casks []string) error {
 	}
 	fmt.Println(successStyle.Render("✓ Wallpaper casks installed!"))
 
-	// macOS specific instructions
+	if err := ledger.Record(wallpaperInstalls(casks, before, installedCasks())...); err != nil {
+		fmt.Println(errorStyle.Render(fmt.Sprintf("Warning: failed to record installed casks: %v", err)))
+	}
+
+	home, _ := os.UserHomeDir()
 	if runtime.GOOS == "darwin" {
-		home, _ := os.UserHomeDir()
 		fmt.Println("\n" + infoStyle.Render("Wallpapers installed to: "+filepath.Join(home, "Library/Desktop Pictures")))
 		fmt.Println(infoStyle.Render("To use: System Settings > Wallpaper > Add Folder"))
+	} else {
+		fmt.Println("\n" + infoStyle.Render("Wallpapers installed to: "+filepath.Join(home, ".local/share/backgrounds")))
 	}
+	fmt.Println(infoStyle.Render("To apply one: bluefin-cli wallpapers list-installed, then bluefin-cli wallpapers apply <collection>"))
 
 	return nil
 }
+
+// wallpaperInstalls returns ledger entries for the requested casks that were not installed before
+func wallpaperInstalls(casks []string, before, after map[string]string) []ledger.Entry {
+	var entries []ledger.Entry
+	for _, c := range casks {
+		token := c[strings.LastIndex(c, "/")+1:]
+		version, installed := after[token]
+		if _, existed := before[token]; existed || !installed {
+			continue
+		}
+		entries = append(entries, ledger.Entry{
+			Action:  ledger.ActionInstall,
+			Package: token,
+			Backend: "brew",
+			Version: version,
+			Kind:    "cask",
+			Bundle:  ledger.WallpapersBundle,
+		})
+	}
+	return entries
+}
@@ -0,0 +1,130 @@
+package install
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hanthor/bluefin-cli/internal/ledger"
+)
+
+const bluefinWallpapersCask = `cask "bluefin-wallpapers" do
+  version "2024-12-01"
+  sha256 :no_check
+
+  url "https://github.com/ublue-os/packages/releases/download/bluefin-wallpapers.tar.zstd"
+  name "Bluefin Wallpapers"
+  desc "Dinosaur themed wallpapers for Bluefin"
+  homepage "https://github.com/ublue-os/packages"
+
+  if OS.mac?
+    Dir.glob("#{staged_path}/*").each do |file|
+      artifact file, target: "#{Dir.home}/Library/Desktop Pictures/Bluefin/#{File.basename(file)}"
+    end
+  else
+    destination_dir = "#{Dir.home}/.local/share/backgrounds/bluefin"
+  end
+end
+`
+
+const artworkCask = `cask "aurora-artwork" do
+  version :latest
+  name "Aurora"
+  desc "Artwork for Aurora"
+  homepage "https://getaurora.dev"
+
+  artifact "images", target: "~/.local/share/wallpapers/aurora"
+end
+`
+
+const toolCask = `cask "framework-tool" do
+  version "0.4.0"
+  desc "Utility for Framework laptops"
+  homepage "https://frame.work"
+end
+`
+
+func TestParseCask(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	c := ParseCask(bluefinWallpapersCask)
+	if c.Name != "Bluefin Wallpapers" || c.Version != "2024-12-01" || c.Homepage != "https://github.com/ublue-os/packages" {
+		t.Errorf("Unexpected metadata: %+v", c)
+	}
+	if c.Desc != "Dinosaur themed wallpapers for Bluefin" {
+		t.Errorf("Unexpected desc: %q", c.Desc)
+	}
+
+	want := []string{
+		filepath.Join(home, "Library", "Desktop Pictures", "Bluefin"),
+		filepath.Join(home, ".local", "share", "backgrounds", "bluefin"),
+	}
+	if len(c.Artifacts) != len(want) {
+		t.Fatalf("Expected artifacts %v, got %v", want, c.Artifacts)
+	}
+	for i := range want {
+		if c.Artifacts[i] != want[i] {
+			t.Errorf("Artifact %d = %s, want %s", i, c.Artifacts[i], want[i])
+		}
+	}
+
+	if v := ParseCask(artworkCask).Version; v != "latest" {
+		t.Errorf("Expected symbol version 'latest', got %q", v)
+	}
+}
+
+func TestReadTapCasksFindsWallpapers(t *testing.T) {
+	casksDir := t.TempDir()
+	files := map[string]string{
+		"bluefin-wallpapers.rb": bluefinWallpapersCask,
+		"a/aurora-artwork.rb":   artworkCask,
+		"framework-tool.rb":     toolCask,
+	}
+	for name, content := range files {
+		path := filepath.Join(casksDir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	casks, err := readTapCasks("ublue-os/tap", casksDir)
+	if err != nil {
+		t.Fatalf("readTapCasks() failed: %v", err)
+	}
+	if len(casks) != 3 {
+		t.Fatalf("Expected 3 casks, got %d", len(casks))
+	}
+
+	wallpapers := make(map[string]bool)
+	for _, c := range casks {
+		if c.Tap != "ublue-os/tap" {
+			t.Errorf("Expected tap to be recorded, got %q", c.Tap)
+		}
+		wallpapers[c.Token] = isWallpaperCask(c)
+	}
+
+	if !wallpapers["bluefin-wallpapers"] {
+		t.Error("Expected bluefin-wallpapers to be detected by name")
+	}
+	if !wallpapers["aurora-artwork"] {
+		t.Error("Expected aurora-artwork to be detected by install location")
+	}
+	if wallpapers["framework-tool"] {
+		t.Error("Did not expect framework-tool to be a wallpaper cask")
+	}
+}
+
+func TestWallpaperInstalls(t *testing.T) {
+	before := map[string]string{"bluefin-wallpapers": "2024-12-01"}
+	after := map[string]string{"bluefin-wallpapers": "2024-12-01", "aurora-wallpapers": "2025-01-10"}
+
+	entries := wallpaperInstalls([]string{"bluefin-wallpapers", "ublue-os/tap/aurora-wallpapers", "failed-wallpapers"}, before, after)
+	if len(entries) != 1 {
+		t.Fatalf("Expected only the newly installed cask, got %+v", entries)
+	}
+	if e := entries[0]; e.Package != "aurora-wallpapers" || e.Backend != "brew" || e.Kind != "cask" || e.Version != "2025-01-10" || e.Bundle != ledger.WallpapersBundle {
+		t.Errorf("Unexpected entry: %+v", e)
+	}
+}
@@ -0,0 +1,263 @@
+package ledger
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hanthor/bluefin-cli/internal/env"
+	"github.com/hanthor/bluefin-cli/internal/pkgmgr"
+)
+
+const (
+	ActionInstall = "install"
+	ActionRemove  = "remove"
+)
+
+// WallpapersBundle is the origin recorded for wallpaper casks
+const WallpapersBundle = "wallpapers"
+
+// Entry is one package installed or removed by bluefin-cli
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Action  string    `json:"action"`
+	Package string    `json:"package"`
+	Backend string    `json:"backend"`
+	Version string    `json:"version,omitempty"`
+	Kind    string    `json:"kind,omitempty"` // formula, cask or flatpak for bundle entries
+	Bundle  string    `json:"bundle,omitempty"`
+	Tool    string    `json:"tool,omitempty"`
+	Command string    `json:"command,omitempty"` // bluefin-cli invocation that triggered the change
+}
+
+// command describes the running invocation, e.g. "bluefin-cli install k8s"
+func command() string {
+	return strings.TrimSpace("bluefin-cli " + strings.Join(os.Args[1:], " "))
+}
+
+// Path returns the location of the ledger, a JSON lines file in the state directory
+func Path() (string, error) {
+	dir, err := env.GetStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ledger.jsonl"), nil
+}
+
+// Record appends entries to the ledger, stamping them with the current time and command
+func Record(entries ...Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	if _, err := env.EnsureStateDir(); err != nil {
+		return err
+	}
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open ledger: %w", err)
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	for _, e := range entries {
+		if e.Time.IsZero() {
+			e.Time = time.Now()
+		}
+		if e.Command == "" {
+			e.Command = command()
+		}
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("failed to write ledger: %w", err)
+		}
+	}
+	return nil
+}
+
+// Read returns every ledger entry in the order it was recorded
+func Read() ([]Entry, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open ledger: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			// Skip a torn line rather than losing the whole history
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// Installed returns the install entries for packages that have not been removed since
+func Installed() ([]Entry, error) {
+	entries, err := Read()
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct{ backend, pkg string }
+	current := make(map[key]int)
+	var order []key
+
+	for i, e := range entries {
+		k := key{e.Backend, e.Package}
+		switch e.Action {
+		case ActionInstall:
+			if _, ok := current[k]; !ok {
+				order = append(order, k)
+			}
+			current[k] = i
+		case ActionRemove:
+			delete(current, k)
+		}
+	}
+
+	var installed []Entry
+	for _, k := range order {
+		if i, ok := current[k]; ok {
+			installed = append(installed, entries[i])
+		}
+	}
+	return installed, nil
+}
+
+// Filter returns the entries for which keep returns true
+func Filter(entries []Entry, keep func(Entry) bool) []Entry {
+	var filtered []Entry
+	for _, e := range entries {
+		if keep(e) {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+// Query selects ledger entries; zero fields match everything
+type Query struct {
+	Bundle string
+	Tool   string
+	Since  time.Time
+	Until  time.Time
+}
+
+// Match reports whether e satisfies every set field of q
+func (q Query) Match(e Entry) bool {
+	if q.Bundle != "" && e.Bundle != q.Bundle {
+		return false
+	}
+	if q.Tool != "" && !strings.EqualFold(e.Tool, q.Tool) {
+		return false
+	}
+	if !q.Since.IsZero() && e.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !e.Time.Before(q.Until) {
+		return false
+	}
+	return true
+}
+
+// ParseTime accepts a date (2006-01-02), an RFC 3339 timestamp, or an age such as "7d" or "12h".
+// With endOfDay set, a plain date means the end of that day, so it can serve as an inclusive upper bound.
+func ParseTime(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		if endOfDay {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return time.Now().AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return time.Now().Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use YYYY-MM-DD, RFC 3339, or an age like 7d or 12h)", value)
+}
+
+// Uninstall removes packages through the backend that installed them and records the removals.
+// Packages that are already gone are only recorded. Entries from backends that cannot uninstall,
+// such as upstream install scripts, are skipped and reported in the returned error.
+func Uninstall(entries []Entry) error {
+	byBackend := make(map[string][]Entry)
+	var backends []string
+	for _, e := range entries {
+		if _, ok := byBackend[e.Backend]; !ok {
+			backends = append(backends, e.Backend)
+		}
+		byBackend[e.Backend] = append(byBackend[e.Backend], e)
+	}
+
+	var skipped []string
+	for _, backend := range backends {
+		pm, ok := pkgmgr.Lookup(backend)
+		if !ok {
+			for _, e := range byBackend[backend] {
+				skipped = append(skipped, fmt.Sprintf("%s (%s)", e.Package, backend))
+			}
+			continue
+		}
+
+		var pkgs []string
+		for _, e := range byBackend[backend] {
+			if pm.IsInstalled(e.Package) {
+				pkgs = append(pkgs, e.Package)
+			}
+		}
+		if len(pkgs) > 0 {
+			if err := pm.Uninstall(pkgs...); err != nil {
+				return err
+			}
+		}
+
+		var removals []Entry
+		for _, e := range byBackend[backend] {
+			removals = append(removals, Entry{
+				Action:  ActionRemove,
+				Package: e.Package,
+				Backend: e.Backend,
+				Version: e.Version,
+				Kind:    e.Kind,
+				Bundle:  e.Bundle,
+				Tool:    e.Tool,
+			})
+		}
+		if err := Record(removals...); err != nil {
+			return err
+		}
+	}
+
+	if len(skipped) > 0 {
+		return fmt.Errorf("cannot uninstall packages from unknown backends, remove them manually: %s", strings.Join(skipped, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package ledger
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hanthor/bluefin-cli/internal/pkgmgr"
+)
+
+// fakeBackend records uninstalls and reports every package but "gone" as installed
+type fakeBackend struct {
+	removed *[]string
+}
+
+func (fakeBackend) Name() string                   { return "fake" }
+func (fakeBackend) Detect() bool                   { return true }
+func (fakeBackend) IsInstalled(pkg string) bool    { return pkg != "gone" }
+func (fakeBackend) Install(pkgs ...string) error   { return nil }
+func (fakeBackend) Version(string) (string, error) { return "1.0", nil }
+func (f fakeBackend) Uninstall(pkgs ...string) error {
+	*f.removed = append(*f.removed, pkgs...)
+	return nil
+}
+
+func TestInstalled(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	entries, err := Installed()
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Expected empty ledger, got %v (err %v)", entries, err)
+	}
+
+	if err := Record(
+		Entry{Action: ActionInstall, Package: "bat", Backend: "brew", Tool: "Bat"},
+		Entry{Action: ActionInstall, Package: "k9s", Backend: "brew", Kind: "formula", Bundle: "k8s"},
+		Entry{Action: ActionInstall, Package: "org.gnome.Boxes", Backend: "flatpak", Kind: "flatpak", Bundle: "full-desktop"},
+		Entry{Action: ActionRemove, Package: "bat", Backend: "brew", Tool: "Bat"},
+	); err != nil {
+		t.Fatalf("Record() failed: %v", err)
+	}
+
+	entries, err = Installed()
+	if err != nil {
+		t.Fatalf("Installed() failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Package != "k9s" || entries[1].Package != "org.gnome.Boxes" {
+		t.Fatalf("Unexpected installed entries: %+v", entries)
+	}
+	if entries[0].Time.IsZero() {
+		t.Error("Expected Record to stamp the time")
+	}
+	if !strings.HasPrefix(entries[0].Command, "bluefin-cli") {
+		t.Errorf("Expected Record to fill in the command, got %q", entries[0].Command)
+	}
+
+	k8s := Filter(entries, func(e Entry) bool { return e.Bundle == "k8s" })
+	if len(k8s) != 1 || k8s[0].Package != "k9s" {
+		t.Errorf("Filter() = %+v", k8s)
+	}
+}
+
+func TestUninstall(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	var removed []string
+	pkgmgr.Register(fakeBackend{removed: &removed})
+
+	entries := []Entry{
+		{Action: ActionInstall, Package: "k9s", Backend: "fake", Bundle: "k8s"},
+		{Action: ActionInstall, Package: "gone", Backend: "fake", Bundle: "k8s"},
+	}
+	if err := Record(entries...); err != nil {
+		t.Fatalf("Record() failed: %v", err)
+	}
+
+	if err := Uninstall(entries); err != nil {
+		t.Fatalf("Uninstall() failed: %v", err)
+	}
+	if len(removed) != 1 || removed[0] != "k9s" {
+		t.Errorf("Expected only k9s to be uninstalled, got %v", removed)
+	}
+
+	left, err := Installed()
+	if err != nil {
+		t.Fatalf("Installed() failed: %v", err)
+	}
+	if len(left) != 0 {
+		t.Errorf("Expected removals to be recorded, still installed: %+v", left)
+	}
+
+	if err := Uninstall([]Entry{{Package: "x", Backend: "missing"}}); err == nil || !strings.Contains(err.Error(), "x (missing)") {
+		t.Errorf("Expected an error naming the skipped package, got %v", err)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	day := time.Date(2025, 3, 10, 15, 0, 0, 0, time.Local)
+	e := Entry{Time: day, Package: "k9s", Bundle: "k8s"}
+
+	until, err := ParseTime("2025-03-10", true)
+	if err != nil {
+		t.Fatalf("ParseTime() failed: %v", err)
+	}
+	since, err := ParseTime("2025-03-10", false)
+	if err != nil {
+		t.Fatalf("ParseTime() failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		query Query
+		want  bool
+	}{
+		{"empty", Query{}, true},
+		{"bundle", Query{Bundle: "k8s"}, true},
+		{"other bundle", Query{Bundle: "cli"}, false},
+		{"same day", Query{Since: since, Until: until}, true},
+		{"before", Query{Until: since}, false},
+		{"tool", Query{Tool: "bat"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.query.Match(e); got != tt.want {
+			t.Errorf("%s: Match() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	if got, err := ParseTime("7d", false); err != nil || time.Since(got) < 7*24*time.Hour-time.Minute {
+		t.Errorf("ParseTime(7d) = %v, %v", got, err)
+	}
+	if got, err := ParseTime("12h", false); err != nil || time.Since(got) < 12*time.Hour-time.Minute {
+		t.Errorf("ParseTime(12h) = %v, %v", got, err)
+	}
+	if _, err := ParseTime("2025-03-10T08:00:00Z", false); err != nil {
+		t.Errorf("ParseTime(RFC 3339) failed: %v", err)
+	}
+	for _, value := range []string{"last tuesday", "1.5d", "3xd", "-2d"} {
+		if _, err := ParseTime(value, false); err == nil {
+			t.Errorf("Expected an error for %q", value)
+		}
+	}
+}
@@ -0,0 +1,438 @@
+package motd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hanthor/bluefin-cli/internal/env"
+	"github.com/hanthor/bluefin-cli/internal/httpclient"
+)
+
+// feedsMaxAge is how old the cached feeds may get before the MOTD refreshes them
+const feedsMaxAge = time.Hour
+
+// announcementMaxAge hides items without an expiry date this long after they were published
+const announcementMaxAge = 14 * 24 * time.Hour
+
+// maxBodyLength keeps announcements short enough for a MOTD
+const maxBodyLength = 280
+
+// httpGet fetches feeds, replaced in tests
+var httpGet = httpclient.Get
+
+// Announcement is one item of a feed listed in motd.json
+type Announcement struct {
+	// ID is a short hash of the feed and item, used to dismiss it
+	ID        string    `json:"id"`
+	Feed      string    `json:"feed"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body,omitempty"`
+	URL       string    `json:"url,omitempty"`
+	Published time.Time `json:"published,omitempty"`
+	// Expires hides the announcement after this time
+	Expires time.Time `json:"expires,omitempty"`
+}
+
+// Expired reports whether the announcement is past its expiry date, or published more than
+// two weeks ago when it has none
+func (a Announcement) Expired(now time.Time) bool {
+	if !a.Expires.IsZero() {
+		return now.After(a.Expires)
+	}
+	return !a.Published.IsZero() && now.Sub(a.Published) > announcementMaxAge
+}
+
+// feedCache holds the last fetch of every feed
+type feedCache struct {
+	Feeds map[string]cachedFeed `json:"feeds"`
+}
+
+type cachedFeed struct {
+	Fetched time.Time      `json:"fetched"`
+	Error   string         `json:"error,omitempty"`
+	Items   []Announcement `json:"items"`
+}
+
+// jsonFeed is the JSON feed format:
+//
+//	{"items": [{"id": "vpn-0612", "title": "VPN maintenance tonight", "body": "...",
+//	  "url": "https://...", "published": "2025-06-12T09:00:00Z", "expires": "2025-06-13T06:00:00Z"}]}
+//
+// A bare array of items is accepted too.
+type jsonFeed struct {
+	Items []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	URL       string    `json:"url"`
+	Published time.Time `json:"published"`
+	Expires   time.Time `json:"expires"`
+}
+
+type rssFeed struct {
+	Items []struct {
+		GUID        string `xml:"guid"`
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		Description string `xml:"description"`
+		PubDate     string `xml:"pubDate"`
+		// Expires is not part of RSS 2.0, but lets RSS feeds set an expiry date too
+		Expires string `xml:"expires"`
+	} `xml:"channel>item"`
+}
+
+type atomFeed struct {
+	Entries []struct {
+		ID      string `xml:"id"`
+		Title   string `xml:"title"`
+		Summary string `xml:"summary"`
+		Content string `xml:"content"`
+		Links   []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Published string `xml:"published"`
+		Updated   string `xml:"updated"`
+		Expires   string `xml:"expires"`
+	} `xml:"entry"`
+}
+
+// ParseFeed reads a JSON, RSS 2.0 or Atom feed
+func ParseFeed(feedURL string, data []byte) ([]Announcement, error) {
+	var items []Announcement
+	add := func(id, title, body, link string, published, expires time.Time) {
+		title, body = strings.TrimSpace(plainText(title)), strings.TrimSpace(plainText(body))
+		if title == "" && body == "" {
+			return
+		}
+		if id == "" {
+			id = link + "\n" + title
+		}
+		if len([]rune(body)) > maxBodyLength {
+			body = string([]rune(body)[:maxBodyLength-1]) + "…"
+		}
+		items = append(items, Announcement{
+			ID:        announcementID(feedURL, id),
+			Feed:      feedURL,
+			Title:     title,
+			Body:      body,
+			URL:       strings.TrimSpace(link),
+			Published: published,
+			Expires:   expires,
+		})
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")), bytes.HasPrefix(trimmed, []byte("[")):
+		var feed jsonFeed
+		if trimmed[0] == '[' {
+			if err := json.Unmarshal(trimmed, &feed.Items); err != nil {
+				return nil, fmt.Errorf("invalid JSON feed: %w", err)
+			}
+		} else if err := json.Unmarshal(trimmed, &feed); err != nil {
+			return nil, fmt.Errorf("invalid JSON feed: %w", err)
+		}
+		for _, it := range feed.Items {
+			add(it.ID, it.Title, it.Body, it.URL, it.Published, it.Expires)
+		}
+		return items, nil
+	}
+
+	var root struct{ XMLName xml.Name }
+	if err := xml.Unmarshal(trimmed, &root); err != nil {
+		return nil, fmt.Errorf("feed is neither JSON nor XML: %w", err)
+	}
+	switch root.XMLName.Local {
+	case "rss":
+		var feed rssFeed
+		if err := xml.Unmarshal(trimmed, &feed); err != nil {
+			return nil, fmt.Errorf("invalid RSS feed: %w", err)
+		}
+		for _, it := range feed.Items {
+			add(it.GUID, it.Title, it.Description, it.Link, parseFeedTime(it.PubDate), parseFeedTime(it.Expires))
+		}
+	case "feed":
+		var feed atomFeed
+		if err := xml.Unmarshal(trimmed, &feed); err != nil {
+			return nil, fmt.Errorf("invalid Atom feed: %w", err)
+		}
+		for _, e := range feed.Entries {
+			link := ""
+			for _, l := range e.Links {
+				if l.Rel == "" || l.Rel == "alternate" {
+					link = l.Href
+					break
+				}
+			}
+			body := e.Summary
+			if body == "" {
+				body = e.Content
+			}
+			published := parseFeedTime(e.Published)
+			if published.IsZero() {
+				published = parseFeedTime(e.Updated)
+			}
+			add(e.ID, e.Title, body, link, published, parseFeedTime(e.Expires))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported feed format <%s>", root.XMLName.Local)
+	}
+	return items, nil
+}
+
+var feedTimeLayouts = []string{time.RFC3339, time.RFC1123Z, time.RFC1123, "Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST", "2006-01-02"}
+
+func parseFeedTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range feedTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// plainText strips the HTML RSS and Atom descriptions often contain
+func plainText(s string) string {
+	s = html.UnescapeString(htmlTag.ReplaceAllString(s, " "))
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func announcementID(feedURL, itemID string) string {
+	sum := sha256.Sum256([]byte(feedURL + "\n" + itemID))
+	return hex.EncodeToString(sum[:4])
+}
+
+func feedCachePath() (string, error) {
+	dir, err := env.GetCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "feeds.json"), nil
+}
+
+func readFeedCache() feedCache {
+	cache := feedCache{Feeds: make(map[string]cachedFeed)}
+	if path, err := feedCachePath(); err == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			json.Unmarshal(data, &cache)
+		}
+	}
+	if cache.Feeds == nil {
+		cache.Feeds = make(map[string]cachedFeed)
+	}
+	return cache
+}
+
+// RefreshFeeds fetches every feed in motd.json and caches the items. A feed that fails keeps
+// its previously cached items, and the errors are returned together.
+func RefreshFeeds(config Config) error {
+	old := readFeedCache()
+	cache := feedCache{Feeds: make(map[string]cachedFeed)}
+
+	var errs []string
+	for _, url := range config.Feeds {
+		items, err := fetchFeed(url)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", url, err))
+			prev := old.Feeds[url]
+			cache.Feeds[url] = cachedFeed{Fetched: time.Now(), Error: err.Error(), Items: prev.Items}
+			continue
+		}
+		cache.Feeds[url] = cachedFeed{Fetched: time.Now(), Items: items}
+	}
+
+	if _, err := env.EnsureCacheDir(); err != nil {
+		return err
+	}
+	path, err := feedCachePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := env.WriteFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to cache feeds: %w", err)
+	}
+	os.Remove(path + ".lock")
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to fetch feeds:\n  %s", strings.Join(errs, "\n  "))
+	}
+	return nil
+}
+
+func fetchFeed(url string) ([]Announcement, error) {
+	resp, err := httpGet(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	return ParseFeed(url, data)
+}
+
+// refreshFeedsIfStale starts a background refresh when a feed was never fetched or its
+// cache is older than feedsMaxAge, so the MOTD never waits for the network
+func refreshFeedsIfStale(config Config) {
+	if len(config.Feeds) == 0 {
+		return
+	}
+	cache := readFeedCache()
+	stale := false
+	for _, url := range config.Feeds {
+		if f, ok := cache.Feeds[url]; !ok || time.Since(f.Fetched) >= feedsMaxAge {
+			stale = true
+		}
+	}
+	if !stale {
+		return
+	}
+	if path, err := feedCachePath(); err == nil {
+		refreshInBackground(path+".lock", "motd", "feeds", "refresh")
+	}
+}
+
+// Announcements returns the cached items of the configured feeds that haven't expired,
+// newest first, marking whether each was dismissed
+func Announcements(config Config) (items []Announcement, dismissed map[string]bool) {
+	cache := readFeedCache()
+	now := time.Now()
+	for _, url := range config.Feeds {
+		for _, item := range cache.Feeds[url].Items {
+			if !item.Expired(now) {
+				items = append(items, item)
+			}
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Published.After(items[j].Published) })
+	return items, readDismissed()
+}
+
+// unreadAnnouncements returns at most config.MaxAnnouncements announcements not dismissed
+func unreadAnnouncements(config Config) []Announcement {
+	items, dismissed := Announcements(config)
+	var unread []Announcement
+	for _, item := range items {
+		if len(unread) >= config.MaxAnnouncements {
+			break
+		}
+		if !dismissed[item.ID] {
+			unread = append(unread, item)
+		}
+	}
+	return unread
+}
+
+// dismissedState records when each dismissed announcement was dismissed
+type dismissedState struct {
+	Dismissed map[string]time.Time `json:"dismissed"`
+}
+
+func dismissedPath() (string, error) {
+	dir, err := env.GetStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "dismissed.json"), nil
+}
+
+func readDismissed() map[string]bool {
+	state := readDismissedState()
+	dismissed := make(map[string]bool)
+	for id := range state.Dismissed {
+		dismissed[id] = true
+	}
+	return dismissed
+}
+
+func readDismissedState() dismissedState {
+	state := dismissedState{}
+	if path, err := dismissedPath(); err == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			json.Unmarshal(data, &state)
+		}
+	}
+	if state.Dismissed == nil {
+		state.Dismissed = make(map[string]time.Time)
+	}
+	return state
+}
+
+// Dismiss hides announcements from the MOTD by ID
+func Dismiss(ids ...string) error {
+	state := readDismissedState()
+	now := time.Now()
+	pruneDismissed(state, readFeedCache(), now)
+	for _, id := range ids {
+		state.Dismissed[id] = now
+	}
+
+	if _, err := env.EnsureStateDir(); err != nil {
+		return err
+	}
+	path, err := dismissedPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return env.WriteFileAtomic(path, data, 0644)
+}
+
+// pruneDismissed forgets dismissed IDs that are no longer needed: items that have expired,
+// and items a successful fetch since the dismissal no longer lists. Items without a date
+// stay dismissed for as long as their feed lists them.
+func pruneDismissed(state dismissedState, cache feedCache, now time.Time) {
+	listed := make(map[string]Announcement)
+	for _, feed := range cache.Feeds {
+		for _, item := range feed.Items {
+			listed[item.ID] = item
+		}
+	}
+
+	for id, at := range state.Dismissed {
+		if item, ok := listed[id]; ok {
+			if item.Expired(now) {
+				delete(state.Dismissed, id)
+			}
+			continue
+		}
+		// Without a fetch since the dismissal the cache may just be missing or outdated
+		for _, feed := range cache.Feeds {
+			if feed.Error == "" && feed.Fetched.After(at) {
+				delete(state.Dismissed, id)
+				break
+			}
+		}
+	}
+}
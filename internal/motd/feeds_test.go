@@ -0,0 +1,226 @@
+package motd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFeed(t *testing.T) {
+	published := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"json", fmt.Sprintf(`{"items": [{"id": "vpn", "title": "VPN maintenance", "body": "Tonight <b>22:00</b>", "url": "https://example.com/vpn", "published": %q}]}`, published.Format(time.RFC3339))},
+		{"json array", fmt.Sprintf(`[{"id": "vpn", "title": "VPN maintenance", "body": "Tonight 22:00", "url": "https://example.com/vpn", "published": %q}]`, published.Format(time.RFC3339))},
+		{"rss", fmt.Sprintf(`<?xml version="1.0"?><rss version="2.0"><channel><title>IT</title>
+<item><guid>vpn</guid><title>VPN maintenance</title><link>https://example.com/vpn</link>
+<description>&lt;p&gt;Tonight 22:00&lt;/p&gt;</description><pubDate>%s</pubDate></item></channel></rss>`, published.Format(time.RFC1123Z))},
+		{"atom", fmt.Sprintf(`<?xml version="1.0"?><feed xmlns="http://www.w3.org/2005/Atom"><title>IT</title>
+<entry><id>vpn</id><title>VPN maintenance</title><link href="https://example.com/vpn"/>
+<summary>Tonight 22:00</summary><updated>%s</updated></entry></feed>`, published.Format(time.RFC3339))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := ParseFeed("https://example.com/feed", []byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(items) != 1 {
+				t.Fatalf("Expected 1 item, got %d", len(items))
+			}
+			item := items[0]
+			if item.Title != "VPN maintenance" || item.Body != "Tonight 22:00" || item.URL != "https://example.com/vpn" {
+				t.Errorf("Unexpected item %+v", item)
+			}
+			if !item.Published.Equal(published) {
+				t.Errorf("Published = %v, want %v", item.Published, published)
+			}
+			if item.ID != announcementID("https://example.com/feed", "vpn") {
+				t.Errorf("Unexpected ID %q", item.ID)
+			}
+		})
+	}
+
+	if _, err := ParseFeed("https://example.com/feed", []byte("<html></html>")); err == nil {
+		t.Error("Expected an HTML page to be rejected")
+	}
+	if _, err := ParseFeed("https://example.com/feed", []byte("not a feed")); err == nil {
+		t.Error("Expected garbage to be rejected")
+	}
+}
+
+func TestAnnouncementExpired(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		item Announcement
+		want bool
+	}{
+		{Announcement{}, false},
+		{Announcement{Expires: now.Add(time.Hour)}, false},
+		{Announcement{Expires: now.Add(-time.Hour)}, true},
+		{Announcement{Published: now.Add(-24 * time.Hour)}, false},
+		{Announcement{Published: now.Add(-30 * 24 * time.Hour)}, true},
+		// An explicit expiry date wins over the age of the item
+		{Announcement{Published: now.Add(-30 * 24 * time.Hour), Expires: now.Add(time.Hour)}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.item.Expired(now); got != tt.want {
+			t.Errorf("%+v.Expired() = %v, want %v", tt.item, got, tt.want)
+		}
+	}
+}
+
+func stubFeeds(t *testing.T, feeds map[string]string) {
+	t.Helper()
+	origHTTPGet := httpGet
+	t.Cleanup(func() { httpGet = origHTTPGet })
+	httpGet = func(url string) (*http.Response, error) {
+		body, ok := feeds[url]
+		if !ok {
+			return &http.Response{StatusCode: http.StatusNotFound, Status: "404 Not Found", Body: io.NopCloser(strings.NewReader(""))}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Body: io.NopCloser(strings.NewReader(body))}, nil
+	}
+}
+
+func TestRefreshFeedsAndDismiss(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	day := func(n int) string { return time.Now().Add(time.Duration(-n) * 24 * time.Hour).Format(time.RFC3339) }
+	feeds := map[string]string{
+		"https://example.com/it.json": fmt.Sprintf(`{"items": [
+			{"id": "a", "title": "Oldest", "published": %q},
+			{"id": "b", "title": "Newest", "published": %q},
+			{"id": "c", "title": "Expired", "published": %q, "expires": %q},
+			{"id": "d", "title": "Middle", "published": %q}]}`, day(3), day(0), day(1), day(0), day(2)),
+	}
+	stubFeeds(t, feeds)
+
+	config := DefaultConfig()
+	config.Feeds = []string{"https://example.com/it.json"}
+	config.MaxAnnouncements = 2
+
+	if unread := unreadAnnouncements(config); len(unread) != 0 {
+		t.Fatalf("Expected no announcements before the first refresh, got %+v", unread)
+	}
+	if err := RefreshFeeds(config); err != nil {
+		t.Fatal(err)
+	}
+
+	items, _ := Announcements(config)
+	var titles []string
+	for _, item := range items {
+		titles = append(titles, item.Title)
+	}
+	if got := strings.Join(titles, ","); got != "Newest,Middle,Oldest" {
+		t.Errorf("Announcements = %s, want newest first without the expired item", got)
+	}
+
+	unread := unreadAnnouncements(config)
+	if len(unread) != 2 || unread[0].Title != "Newest" {
+		t.Fatalf("Expected the 2 newest announcements, got %+v", unread)
+	}
+	if err := Dismiss(unread[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	unread = unreadAnnouncements(config)
+	if len(unread) != 2 || unread[0].Title != "Middle" || unread[1].Title != "Oldest" {
+		t.Errorf("Expected the dismissed announcement to be skipped, got %+v", unread)
+	}
+
+	// A feed that fails keeps the items of the last successful fetch
+	delete(feeds, "https://example.com/it.json")
+	config.Feeds = append(config.Feeds, "https://example.com/missing.json")
+	if err := RefreshFeeds(config); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected the failed fetches to be reported, got %v", err)
+	}
+	if items, _ := Announcements(config); len(items) != 3 {
+		t.Errorf("Expected the cached items to be kept, got %d", len(items))
+	}
+}
+
+func TestRefreshFeedsIfStale(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	stubFeeds(t, map[string]string{"https://example.com/it.json": `{"items": []}`})
+	origStartBackground := startBackground
+	defer func() { startBackground = origStartBackground }()
+
+	started := 0
+	startBackground = func(args ...string) error {
+		if strings.Join(args, " ") != "motd feeds refresh" {
+			t.Errorf("Unexpected command %v", args)
+		}
+		started++
+		return nil
+	}
+
+	config := DefaultConfig()
+	refreshFeedsIfStale(config)
+	if started != 0 {
+		t.Error("Expected no refresh without feeds")
+	}
+
+	config.Feeds = []string{"https://example.com/it.json"}
+	refreshFeedsIfStale(config)
+	refreshFeedsIfStale(config)
+	if started != 1 {
+		t.Errorf("Expected one refresh while the first is running, got %d", started)
+	}
+
+	if err := RefreshFeeds(config); err != nil {
+		t.Fatal(err)
+	}
+	refreshFeedsIfStale(config)
+	if started != 1 {
+		t.Errorf("Expected no refresh of a fresh cache, got %d", started)
+	}
+
+	// A feed added since the last refresh is fetched
+	config.Feeds = append(config.Feeds, "https://example.com/new.json")
+	refreshFeedsIfStale(config)
+	if started != 2 {
+		t.Errorf("Expected a refresh for the new feed, got %d", started)
+	}
+}
+
+func TestPruneDismissed(t *testing.T) {
+	now := time.Now()
+	month := 30 * 24 * time.Hour
+	cache := feedCache{Feeds: map[string]cachedFeed{
+		"https://example.com/it.json": {Fetched: now.Add(-time.Hour), Items: []Announcement{
+			{ID: "undated"},
+			{ID: "far-expiry", Expires: now.Add(month)},
+			{ID: "expired", Expires: now.Add(-time.Hour)},
+		}},
+	}}
+	state := dismissedState{Dismissed: map[string]time.Time{
+		// Dismissed long ago, but still listed and not expired
+		"undated":    now.Add(-2 * month),
+		"far-expiry": now.Add(-2 * month),
+		"expired":    now.Add(-2 * month),
+		// Gone from the feed since the last fetch
+		"removed": now.Add(-2 * time.Hour),
+		// Dismissed after the last fetch, so the cache can't tell yet
+		"recent": now.Add(-time.Minute),
+	}}
+
+	pruneDismissed(state, cache, now)
+
+	var kept []string
+	for id := range state.Dismissed {
+		kept = append(kept, id)
+	}
+	sort.Strings(kept)
+	if got := strings.Join(kept, ","); got != "far-expiry,recent,undated" {
+		t.Errorf("Kept %s, want far-expiry,recent,undated", got)
+	}
+}
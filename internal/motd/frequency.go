@@ -0,0 +1,166 @@
+package motd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hanthor/bluefin-cli/internal/env"
+)
+
+// SuppressEnv hides the MOTD of a single shell when set to anything but "0" or "false"
+const SuppressEnv = "BLUEFIN_CLI_NO_MOTD"
+
+// Frequencies other than a duration such as "4h"
+const (
+	FrequencyAlways  = "always"
+	FrequencySession = "session"
+	FrequencyDaily   = "daily"
+)
+
+// terminalEnvironments detects the terminals the MOTD can be suppressed in, by name
+var terminalEnvironments = map[string]func() bool{
+	"tmux":   func() bool { return os.Getenv("TMUX") != "" },
+	"screen": func() bool { return os.Getenv("STY") != "" },
+	"zellij": func() bool { return os.Getenv("ZELLIJ") != "" },
+	"vscode": func() bool { return os.Getenv("TERM_PROGRAM") == "vscode" },
+}
+
+// shownState records when the MOTD was last shown at shell startup
+type shownState struct {
+	Time    time.Time `json:"time"`
+	Session string    `json:"session"`
+}
+
+// ValidateFrequency checks a frequency: always, session, daily or a duration like "4h"
+func ValidateFrequency(frequency string) error {
+	switch frequency {
+	case "", FrequencyAlways, FrequencySession, FrequencyDaily:
+		return nil
+	}
+	if d, err := time.ParseDuration(frequency); err != nil || d <= 0 {
+		return fmt.Errorf("invalid MOTD frequency %q: use always, session, daily or a duration like 4h", frequency)
+	}
+	return nil
+}
+
+// ShowAtStartup shows the MOTD from the shell hook in format, unless it is suppressed for
+// this terminal or was already shown as often as the configured frequency allows
+func ShowAtStartup(format string) error {
+	config, err := LoadConfig()
+	if err != nil {
+		config = DefaultConfig()
+	}
+	if suppressed(config) != "" {
+		return nil
+	}
+
+	session := loginSession()
+	last, _ := readShownState()
+	if !due(config.Frequency, last, time.Now(), session) {
+		return nil
+	}
+
+	if err := ShowFormat(format); err != nil {
+		return err
+	}
+	return writeShownState(shownState{Time: time.Now(), Session: session})
+}
+
+// suppressed returns why the MOTD is hidden in this shell, or "" when it isn't
+func suppressed(config Config) string {
+	if v := os.Getenv(SuppressEnv); v != "" && v != "0" && v != "false" {
+		return SuppressEnv
+	}
+	for _, name := range config.SuppressIn {
+		if detect, ok := terminalEnvironments[name]; ok && detect() {
+			return name
+		}
+	}
+	return ""
+}
+
+// due reports whether the MOTD should be shown now, given when it was last shown
+func due(frequency string, last shownState, now time.Time, session string) bool {
+	if last.Time.IsZero() {
+		return true
+	}
+	switch frequency {
+	case "", FrequencyAlways:
+		return true
+	case FrequencySession:
+		return last.Session != session
+	case FrequencyDaily:
+		y1, m1, d1 := last.Time.Local().Date()
+		y2, m2, d2 := now.Local().Date()
+		return y1 != y2 || m1 != m2 || d1 != d2
+	}
+	d, err := time.ParseDuration(frequency)
+	if err != nil {
+		return true
+	}
+	return now.Sub(last.Time) >= d
+}
+
+// loginSession identifies the login session: the systemd-logind session on the current
+// boot, or just the boot where there is no logind session
+func loginSession() string {
+	boot := bootID()
+	if id := os.Getenv("XDG_SESSION_ID"); id != "" {
+		return boot + "/" + id
+	}
+	return boot
+}
+
+// bootID identifies the current boot by the kernel's boot ID, or the boot time where the
+// kernel has none
+func bootID() string {
+	if raw, err := os.ReadFile(filepath.Join(procRoot, "sys", "kernel", "random", "boot_id")); err == nil {
+		return strings.TrimSpace(string(raw))
+	}
+	if up, ok := uptime(); ok {
+		// Boot time, to the minute so it is stable between calls
+		return time.Now().Add(-up).Truncate(time.Minute).UTC().Format(time.RFC3339)
+	}
+	return ""
+}
+
+func shownStatePath() (string, error) {
+	dir, err := env.GetStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "motd-shown.json"), nil
+}
+
+func readShownState() (shownState, error) {
+	var state shownState
+	path, err := shownStatePath()
+	if err != nil {
+		return state, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state, err
+	}
+	err = json.Unmarshal(data, &state)
+	return state, err
+}
+
+func writeShownState(state shownState) error {
+	if _, err := env.EnsureStateDir(); err != nil {
+		return err
+	}
+	path, err := shownStatePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return env.WriteFileAtomic(path, data, 0644)
+}
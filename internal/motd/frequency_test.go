@@ -0,0 +1,117 @@
+package motd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDue(t *testing.T) {
+	now := time.Date(2025, time.March, 4, 9, 0, 0, 0, time.Local)
+	tests := []struct {
+		frequency string
+		last      shownState
+		want      bool
+	}{
+		{FrequencyAlways, shownState{Time: now.Add(-time.Second), Session: "a"}, true},
+		{FrequencySession, shownState{}, true},
+		{FrequencySession, shownState{Time: now.Add(-48 * time.Hour), Session: "a"}, false},
+		{FrequencySession, shownState{Time: now.Add(-time.Minute), Session: "b"}, true},
+		{FrequencyDaily, shownState{Time: now.Add(-8 * time.Hour), Session: "a"}, false},
+		{FrequencyDaily, shownState{Time: now.Add(-10 * time.Hour), Session: "a"}, true},
+		{"4h", shownState{Time: now.Add(-3 * time.Hour), Session: "a"}, false},
+		{"4h", shownState{Time: now.Add(-4 * time.Hour), Session: "a"}, true},
+	}
+	for _, tt := range tests {
+		if got := due(tt.frequency, tt.last, now, "a"); got != tt.want {
+			t.Errorf("due(%q, %v) = %v, want %v", tt.frequency, tt.last.Time, got, tt.want)
+		}
+	}
+}
+
+func TestValidateFrequency(t *testing.T) {
+	for _, f := range []string{"always", "session", "daily", "90m", "12h"} {
+		if err := ValidateFrequency(f); err != nil {
+			t.Errorf("ValidateFrequency(%q) failed: %v", f, err)
+		}
+	}
+	for _, f := range []string{"weekly", "-1h", "0s"} {
+		if err := ValidateFrequency(f); err == nil {
+			t.Errorf("Expected %q to be rejected", f)
+		}
+	}
+}
+
+func TestSuppressed(t *testing.T) {
+	for _, name := range []string{SuppressEnv, "TMUX", "STY", "ZELLIJ", "TERM_PROGRAM"} {
+		t.Setenv(name, "")
+	}
+	config := DefaultConfig()
+	if reason := suppressed(config); reason != "" {
+		t.Errorf("Expected the MOTD to be shown, suppressed by %s", reason)
+	}
+
+	t.Setenv("TERM_PROGRAM", "vscode")
+	if reason := suppressed(config); reason != "vscode" {
+		t.Errorf("Expected suppression in VS Code, got %q", reason)
+	}
+	config.SuppressIn = []string{"tmux"}
+	if reason := suppressed(config); reason != "" {
+		t.Errorf("Expected VS Code suppression to be configurable, got %q", reason)
+	}
+
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1234,0")
+	if reason := suppressed(config); reason != "tmux" {
+		t.Errorf("Expected suppression in tmux, got %q", reason)
+	}
+
+	t.Setenv(SuppressEnv, "0")
+	config.SuppressIn = nil
+	if reason := suppressed(config); reason != "" {
+		t.Errorf("Expected %s=0 not to suppress, got %q", SuppressEnv, reason)
+	}
+	t.Setenv(SuppressEnv, "1")
+	if reason := suppressed(config); reason != SuppressEnv {
+		t.Errorf("Expected %s to suppress, got %q", SuppressEnv, reason)
+	}
+}
+
+func TestShowAtStartupOncePerSession(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("HOMEBREW_PREFIX", "")
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv("BLUEFIN_CLI_POLICY", "")
+	t.Setenv("XDG_SESSION_ID", "3")
+	for _, name := range []string{SuppressEnv, "TMUX", "TERM_PROGRAM"} {
+		t.Setenv(name, "")
+	}
+
+	config := DefaultConfig()
+	config.Frequency = FrequencySession
+	if err := SaveConfig(config); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ShowAtStartup(""); err != nil {
+		t.Fatalf("ShowAtStartup failed: %v", err)
+	}
+	first, err := readShownState()
+	if err != nil {
+		t.Fatalf("Expected the shown state to be recorded: %v", err)
+	}
+
+	if err := ShowAtStartup(""); err != nil {
+		t.Fatal(err)
+	}
+	if second, _ := readShownState(); !second.Time.Equal(first.Time) {
+		t.Error("Expected the MOTD not to be shown twice in one session")
+	}
+
+	t.Setenv("XDG_SESSION_ID", "4")
+	if err := ShowAtStartup(""); err != nil {
+		t.Fatal(err)
+	}
+	if third, _ := readShownState(); third.Time.Equal(first.Time) {
+		t.Error("Expected the MOTD to be shown in a new session")
+	}
+}
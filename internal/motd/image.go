@@ -0,0 +1,288 @@
+package motd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/hanthor/bluefin-cli/internal/env"
+)
+
+// deploymentMaxAge is how old the cached deployment may get before the MOTD refreshes it,
+// so a newly staged update shows up soon
+const deploymentMaxAge = 15 * time.Minute
+
+// DefaultImageInfoFile is where Universal Blue images describe themselves
+const DefaultImageInfoFile = "/usr/share/ublue-os/image-info.json"
+
+var osReleasePath = "/etc/os-release"
+
+var lookPath = exec.LookPath
+
+type ImageInfo struct {
+	ImageName     string `json:"image-name"`
+	ImageTag      string `json:"image-tag"`
+	ImageFlavor   string `json:"image-flavor"`
+	ImageVendor   string `json:"image-vendor"`
+	ImageRef      string `json:"image-ref,omitempty"`
+	BaseImageName string `json:"base-image-name,omitempty"`
+	FedoraVersion string `json:"fedora-version"`
+}
+
+// Deployment is the image-based OS deployment reported by rpm-ostree or bootc
+type Deployment struct {
+	// Booted is the image the system is running
+	Booted DeploymentImage `json:"booted"`
+	// Staged is an update that is applied on the next boot, or nil
+	Staged *DeploymentImage `json:"staged,omitempty"`
+}
+
+// DeploymentImage is one deployed image, e.g. ostree-image-signed:docker://ghcr.io/ublue-os/bluefin:stable
+type DeploymentImage struct {
+	Ref     string `json:"ref"`
+	Version string `json:"version,omitempty"`
+}
+
+// getImageInfo reads the Universal Blue image-info.json at path (DefaultImageInfoFile when
+// empty), filling anything it lacks from os-release or, on macOS, sw_vers
+func getImageInfo(path string) ImageInfo {
+	if path == "" {
+		path = DefaultImageInfoFile
+	}
+
+	var info ImageInfo
+	if data, err := os.ReadFile(path); err != nil || json.Unmarshal(data, &info) != nil {
+		// Not a Universal Blue image
+		info = ImageInfo{
+			ImageFlavor:   "homebrew",
+			ImageVendor:   "bluefin-cli",
+			FedoraVersion: "N/A",
+		}
+	}
+
+	if runtime.GOOS == "darwin" && info.ImageName == "" {
+		info.ImageName = "macOS"
+		if output, err := exec.Command("sw_vers", "-productVersion").Output(); err == nil {
+			info.ImageTag = strings.TrimSpace(string(output))
+		}
+	} else if runtime.GOOS == "linux" && (info.ImageName == "" || info.ImageTag == "") {
+		name, version := readOSRelease()
+		if info.ImageName == "" {
+			info.ImageName = name
+		}
+		if info.ImageTag == "" {
+			info.ImageTag = version
+		}
+	}
+
+	if info.ImageName == "" {
+		info.ImageName = runtime.GOOS
+	}
+	if info.ImageTag == "" {
+		info.ImageTag = "unknown"
+	}
+
+	return info
+}
+
+// readOSRelease returns NAME and VERSION_ID from os-release
+func readOSRelease() (name, version string) {
+	data, err := os.ReadFile(osReleasePath)
+	if err != nil {
+		return "", ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "NAME=") {
+			name = strings.Trim(strings.TrimPrefix(line, "NAME="), `"`)
+		} else if strings.HasPrefix(line, "VERSION_ID=") {
+			version = strings.Trim(strings.TrimPrefix(line, "VERSION_ID="), `"`)
+		}
+	}
+	return name, version
+}
+
+// deploymentCache is deployment.json in the state directory
+type deploymentCache struct {
+	Deployment *Deployment `json:"deployment,omitempty"`
+	Checked    time.Time   `json:"checked"`
+	// Boot is the boot the deployment was read on, since the booted image changes on reboot
+	Boot string `json:"boot"`
+}
+
+func deploymentPath() (string, error) {
+	dir, err := env.GetStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "deployment.json"), nil
+}
+
+func readDeploymentCache() *deploymentCache {
+	path, err := deploymentPath()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var cache deploymentCache
+	if err := json.Unmarshal(data, &cache); err != nil || cache.Checked.IsZero() || cache.Boot != bootID() {
+		return nil
+	}
+	return &cache
+}
+
+// imageBased reports whether rpm-ostree or bootc is installed
+func imageBased() bool {
+	for _, tool := range []string{"rpm-ostree", "bootc"} {
+		if _, err := lookPath(tool); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// CachedDeployment returns the deployment from the last refresh on this boot, or nil if there
+// was none or the system is not image based
+func CachedDeployment() *Deployment {
+	if cache := readDeploymentCache(); cache != nil {
+		return cache.Deployment
+	}
+	return nil
+}
+
+// RefreshDeployment asks rpm-ostree or bootc for the deployment now and caches it in the
+// state directory
+func RefreshDeployment() (*Deployment, error) {
+	cache := deploymentCache{Deployment: getDeployment(), Checked: time.Now(), Boot: bootID()}
+
+	if _, err := env.EnsureStateDir(); err != nil {
+		return nil, err
+	}
+	path, err := deploymentPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := env.WriteFileAtomic(path, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to cache deployment: %w", err)
+	}
+	os.Remove(path + ".lock")
+	return cache.Deployment, nil
+}
+
+// refreshDeploymentIfStale starts a background refresh on image-based systems when the
+// cached deployment is missing, from an earlier boot or older than deploymentMaxAge, so the
+// MOTD never waits for rpm-ostree or bootc
+func refreshDeploymentIfStale() {
+	if cache := readDeploymentCache(); cache != nil && time.Since(cache.Checked) < deploymentMaxAge {
+		return
+	}
+	if !imageBased() {
+		return
+	}
+	if path, err := deploymentPath(); err == nil {
+		refreshInBackground(path+".lock", "motd", "deployment", "--refresh")
+	}
+}
+
+// getDeployment asks rpm-ostree, then bootc, for the booted and staged images. It returns
+// nil on systems that are not image based.
+func getDeployment() *Deployment {
+	if _, err := lookPath("rpm-ostree"); err == nil {
+		if out, err := execCommand("rpm-ostree", "status", "--json").Output(); err == nil {
+			if d := parseRpmOstreeStatus(out); d != nil {
+				return d
+			}
+		}
+	}
+	if _, err := lookPath("bootc"); err == nil {
+		if out, err := execCommand("bootc", "status", "--json").Output(); err == nil {
+			return parseBootcStatus(out)
+		}
+	}
+	return nil
+}
+
+func parseRpmOstreeStatus(data []byte) *Deployment {
+	var status struct {
+		Deployments []struct {
+			Booted  bool   `json:"booted"`
+			Staged  bool   `json:"staged"`
+			Ref     string `json:"container-image-reference"`
+			Origin  string `json:"origin"`
+			Version string `json:"version"`
+		} `json:"deployments"`
+	}
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil
+	}
+
+	var d Deployment
+	found := false
+	for _, dep := range status.Deployments {
+		// Package-layered ostree deployments have an origin ref instead of an image
+		ref := dep.Ref
+		if ref == "" {
+			ref = dep.Origin
+		}
+		image := DeploymentImage{Ref: ref, Version: dep.Version}
+		switch {
+		case dep.Booted:
+			d.Booted, found = image, true
+		case dep.Staged:
+			d.Staged = &image
+		}
+	}
+	if !found {
+		return nil
+	}
+	return &d
+}
+
+func parseBootcStatus(data []byte) *Deployment {
+	type bootEntry struct {
+		Image *struct {
+			Image struct {
+				Image     string `json:"image"`
+				Transport string `json:"transport"`
+			} `json:"image"`
+			Version string `json:"version"`
+		} `json:"image"`
+	}
+	var host struct {
+		Status struct {
+			Booted *bootEntry `json:"booted"`
+			Staged *bootEntry `json:"staged"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal(data, &host); err != nil {
+		return nil
+	}
+
+	image := func(e *bootEntry) *DeploymentImage {
+		if e == nil || e.Image == nil {
+			return nil
+		}
+		ref := e.Image.Image.Image
+		if t := e.Image.Image.Transport; t != "" && t != "registry" {
+			ref = t + ":" + ref
+		}
+		return &DeploymentImage{Ref: ref, Version: e.Image.Version}
+	}
+
+	booted := image(host.Status.Booted)
+	if booted == nil {
+		return nil
+	}
+	return &Deployment{Booted: *booted, Staged: image(host.Status.Staged)}
+}
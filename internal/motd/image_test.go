@@ -0,0 +1,174 @@
+package motd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestGetImageInfoFromImageInfoFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "image-info.json")
+	imageInfo := `{
+  "image-name": "bluefin-dx",
+  "image-flavor": "nvidia",
+  "image-vendor": "ublue-os",
+  "image-ref": "ostree-image-signed:docker://ghcr.io/ublue-os/bluefin-dx-nvidia",
+  "image-tag": "stable",
+  "base-image-name": "silverblue",
+  "fedora-version": "42"
+}`
+	if err := os.WriteFile(path, []byte(imageInfo), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	info := getImageInfo(path)
+	want := ImageInfo{
+		ImageName:     "bluefin-dx",
+		ImageTag:      "stable",
+		ImageFlavor:   "nvidia",
+		ImageVendor:   "ublue-os",
+		ImageRef:      "ostree-image-signed:docker://ghcr.io/ublue-os/bluefin-dx-nvidia",
+		BaseImageName: "silverblue",
+		FedoraVersion: "42",
+	}
+	if info != want {
+		t.Errorf("getImageInfo() = %+v, want %+v", info, want)
+	}
+}
+
+func TestGetImageInfoFallsBackToOSRelease(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("os-release is only read on Linux")
+	}
+	dir := t.TempDir()
+	origOSRelease := osReleasePath
+	defer func() { osReleasePath = origOSRelease }()
+	osReleasePath = filepath.Join(dir, "os-release")
+	if err := os.WriteFile(osReleasePath, []byte("NAME=\"Fedora Linux\"\nVERSION_ID=42\nID=fedora\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	info := getImageInfo(filepath.Join(dir, "missing.json"))
+	if info.ImageName != "Fedora Linux" || info.ImageTag != "42" || info.ImageVendor != "bluefin-cli" {
+		t.Errorf("Unexpected fallback info %+v", info)
+	}
+
+	// Fields missing from image-info.json come from os-release
+	partial := filepath.Join(dir, "image-info.json")
+	if err := os.WriteFile(partial, []byte(`{"image-name": "aurora", "image-vendor": "ublue-os"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	info = getImageInfo(partial)
+	if info.ImageName != "aurora" || info.ImageTag != "42" || info.ImageVendor != "ublue-os" {
+		t.Errorf("Unexpected merged info %+v", info)
+	}
+}
+
+func TestParseRpmOstreeStatus(t *testing.T) {
+	status := `{"deployments": [
+  {"booted": false, "staged": true, "container-image-reference": "ostree-image-signed:docker://ghcr.io/ublue-os/bluefin:stable", "version": "42.20250108"},
+  {"booted": true, "staged": false, "container-image-reference": "ostree-image-signed:docker://ghcr.io/ublue-os/bluefin:stable", "version": "42.20250101"},
+  {"booted": false, "staged": false, "container-image-reference": "ostree-image-signed:docker://ghcr.io/ublue-os/bluefin:stable", "version": "42.20241220"}
+]}`
+	d := parseRpmOstreeStatus([]byte(status))
+	if d == nil {
+		t.Fatal("Expected a deployment")
+	}
+	if d.Booted.Version != "42.20250101" || d.Booted.Ref != "ostree-image-signed:docker://ghcr.io/ublue-os/bluefin:stable" {
+		t.Errorf("Unexpected booted image %+v", d.Booted)
+	}
+	if d.Staged == nil || d.Staged.Version != "42.20250108" {
+		t.Errorf("Unexpected staged image %+v", d.Staged)
+	}
+
+	if parseRpmOstreeStatus([]byte(`{"deployments": []}`)) != nil {
+		t.Error("Expected nil without a booted deployment")
+	}
+}
+
+func TestParseBootcStatus(t *testing.T) {
+	status := `{"apiVersion": "org.containers.bootc/v1", "kind": "BootcHost", "status": {
+  "staged": null,
+  "booted": {"image": {"image": {"image": "ghcr.io/ublue-os/aurora:latest", "transport": "registry"}, "version": "42.20250101", "imageDigest": "sha256:abc"}}
+}}`
+	d := parseBootcStatus([]byte(status))
+	if d == nil {
+		t.Fatal("Expected a deployment")
+	}
+	if d.Booted.Ref != "ghcr.io/ublue-os/aurora:latest" || d.Booted.Version != "42.20250101" || d.Staged != nil {
+		t.Errorf("Unexpected deployment %+v", d)
+	}
+}
+
+func TestGetDeploymentNotImageBased(t *testing.T) {
+	origLookPath, origExecCommand := lookPath, execCommand
+	defer func() { lookPath, execCommand = origLookPath, origExecCommand }()
+
+	lookPath = func(file string) (string, error) { return "", errors.New("not found") }
+	execCommand = func(name string, arg ...string) *exec.Cmd {
+		t.Errorf("Unexpected command %s", name)
+		return exec.Command("false")
+	}
+	if d := getDeployment(); d != nil {
+		t.Errorf("Expected no deployment, got %+v", d)
+	}
+}
+
+func TestDeploymentCache(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	fixtureRoot(t, map[string]string{"proc/sys/kernel/random/boot_id": "boot-1\n"})
+	origLookPath, origExecCommand, origStartBackground := lookPath, execCommand, startBackground
+	defer func() { lookPath, execCommand, startBackground = origLookPath, origExecCommand, origStartBackground }()
+
+	lookPath = func(file string) (string, error) {
+		if file == "rpm-ostree" {
+			return "/usr/bin/rpm-ostree", nil
+		}
+		return "", errors.New("not found")
+	}
+	execCommand = func(name string, arg ...string) *exec.Cmd {
+		return exec.Command("echo", `{"deployments": [{"booted": true, "container-image-reference": "ostree-image-signed:docker://ghcr.io/ublue-os/bluefin:stable", "version": "42.20250101"}]}`)
+	}
+	started := 0
+	startBackground = func(args ...string) error {
+		if strings.Join(args, " ") != "motd deployment --refresh" {
+			t.Errorf("Unexpected command %v", args)
+		}
+		started++
+		return nil
+	}
+
+	if d := CachedDeployment(); d != nil {
+		t.Errorf("Expected no deployment before the first refresh, got %+v", d)
+	}
+	refreshDeploymentIfStale()
+	if started != 1 {
+		t.Fatalf("Expected a background refresh, got %d", started)
+	}
+
+	if _, err := RefreshDeployment(); err != nil {
+		t.Fatal(err)
+	}
+	if d := CachedDeployment(); d == nil || d.Booted.Version != "42.20250101" {
+		t.Errorf("Expected the cached deployment, got %+v", d)
+	}
+	refreshDeploymentIfStale()
+	if started != 1 {
+		t.Errorf("Expected no refresh of a fresh cache, got %d", started)
+	}
+
+	// After a reboot the booted image may have changed
+	fixtureRoot(t, map[string]string{"proc/sys/kernel/random/boot_id": "boot-2\n"})
+	if d := CachedDeployment(); d != nil {
+		t.Errorf("Expected the deployment of the last boot to be ignored, got %+v", d)
+	}
+	refreshDeploymentIfStale()
+	if started != 2 {
+		t.Errorf("Expected a refresh after a reboot, got %d", started)
+	}
+}
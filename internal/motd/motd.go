@@ -3,275 +3,439 @@ package motd
 import (
 	"encoding/json"
 	"fmt"
-	"math/rand"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"runtime"
+	"strconv"
 	"strings"
-	"time"
 
 	"github.com/hanthor/bluefin-cli/internal/env"
+	"github.com/hanthor/bluefin-cli/internal/policy"
+	"github.com/hanthor/bluefin-cli/internal/shell"
 	"github.com/hanthor/bluefin-cli/internal/tui"
 )
 
 const motdMarker = "# bluefin-cli motd"
 
-var defaultTips = []string{
-	"Use `brew search` and `brew install` to install packages. Homebrew will take care of updates automatically",
-	"`tldr vim` will give you the basic rundown on commands for a given tool",
-	"Performance profiling tools are built-in: try `top`, `htop`, and other debugging tools",
-	"Switch shells safely: change your shell in Terminal settings instead of system-wide",
-	"Container development is OS-agnostic - your devcontainers work on Linux, macOS, and Windows",
-	"Use `docker compose` for multi-container development if devcontainers don't fit your workflow",
-	"Bluefin separates the OS from your development environment - embrace the cloud-native workflow",
-	"Check out DevPod for open-source, client-only development environments that work with any IDE",
-	"Develop with devcontainers! Use `devcontainer.json` files in your projects for isolated, reproducible environments",
-	"VS Code comes with devcontainers extension pre-installed - perfect for containerized development",
-	"Use `eza -l --icons` for a beautiful file listing with icons and colors",
-	"The `bat` command is like `cat` but with syntax highlighting and Git integration",
-	"Navigate directories faster with `zoxide` - just use `z <partial-name>` to jump around",
-	"Search your shell history with `atuin` using Ctrl+R for a better history search experience",
-	"Customize your prompt with `starship config` to modify colors, icons, and modules",
-}
-
-var defaultTemplate = `# 󱍢 Welcome to Bluefin CLI
-󱋩 %s:%s
-
-|  Command | Description |
-| ------- | ----------- |
-| ` + "`bluefin-cli shell bash on`" + `  | Enable shell experience for bash  |
-| ` + "`bluefin-cli status`" + ` | Show current configuration |
-| ` + "`bluefin-cli help`" + ` | Show all available commands |
-| ` + "`brew help`" + ` | Manage command line packages |
-| ` + "`brew search <query>`" + ` | Search for packages |
-
-%s
-
-- **󰊤** [GitHub Issues](https://github.com/hanthor/bluefin-cli/issues)
-- **󰈙** [Documentation](https://github.com/hanthor/bluefin-cli)
-`
-
-type ImageInfo struct {
-	ImageName     string `json:"image-name"`
-	ImageTag      string `json:"image-tag"`
-	ImageFlavor   string `json:"image-flavor"`
-	ImageVendor   string `json:"image-vendor"`
-	FedoraVersion string `json:"fedora-version"`
-}
-
 type Config struct {
 	TipsDirectory   string `json:"tips-directory"`
-	CheckOutdated   string `json:"check-outdated"`
+	CheckOutdated   bool   `json:"check-outdated"`
 	ImageInfoFile   string `json:"image-info-file"`
 	DefaultTheme    string `json:"default-theme"`
 	TemplateFile    string `json:"template-file"`
 	ThemesDirectory string `json:"themes-directory"`
+	// Widgets are the system information widgets shown above the MOTD, see WidgetNames
+	Widgets []string `json:"widgets"`
+	// Frequency limits how often new shells show the MOTD, see ValidateFrequency
+	Frequency string `json:"frequency"`
+	// SuppressIn lists terminals new shells don't show the MOTD in: tmux, screen, zellij, vscode
+	SuppressIn []string `json:"suppress-in"`
+	// Feeds are JSON, RSS or Atom URLs whose items are shown as announcements
+	Feeds []string `json:"feeds"`
+	// MaxAnnouncements is how many unread announcements the MOTD shows at most
+	MaxAnnouncements int `json:"max-announcements"`
+}
+
+// UnmarshalJSON also accepts check-outdated as the string "true" or "false", as older
+// versions wrote it
+func (c *Config) UnmarshalJSON(data []byte) error {
+	type plain Config
+	aux := struct {
+		*plain
+		CheckOutdated interface{} `json:"check-outdated"`
+	}{plain: (*plain)(c)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	switch v := aux.CheckOutdated.(type) {
+	case nil:
+	case bool:
+		c.CheckOutdated = v
+	case string:
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("check-outdated: %w", err)
+		}
+		c.CheckOutdated = enabled
+	default:
+		return fmt.Errorf("check-outdated must be true or false")
+	}
+	return nil
 }
 
-// Toggle enables or disables MOTD for shells
-// Deprecated: Use 'bluefin-cli init' instead
+// Toggle turns the MOTD on or off in shell.json for one shell, or for every shell with "all".
+// It also removes the lines older versions added to the shell startup files.
 func Toggle(target string, enable bool) error {
-	fmt.Println(tui.InfoStyle.Render("ℹ Note: shell integration is now handled via 'bluefin-cli init'"))
-	
-	// For backward compatibility cleanup, we could implemented removal here, 
-	// but for now, we just inform the user.
+	shells := []string{target}
+	if target == "all" {
+		shells = shell.Shells
+	} else if _, err := shell.RcFile(target); err != nil {
+		return err
+	}
+
+	// Defaults are built for the shell running bluefin-cli; "all" isn't a shell
+	shellName := target
+	if target == "all" {
+		shellName = filepath.Base(os.Getenv("SHELL"))
+	}
+	cfg, err := shell.LoadConfig(shellName)
+	if err != nil {
+		return err
+	}
+	if target == "all" {
+		cfg.SetEnabled("motd", enable)
+		// Per-shell settings would override the new setting
+		for _, sh := range shell.Shells {
+			delete(*cfg, shell.MotdKey(sh))
+		}
+	} else {
+		if pol, _ := policy.Load(); pol.IsLocked("shell.motd") {
+			return fmt.Errorf("locked by policy: shell.motd")
+		}
+		(*cfg)[shell.MotdKey(target)] = enable
+	}
+	if err := shell.SaveConfig(cfg); err != nil {
+		return err
+	}
+
+	state := "disabled"
+	if enable {
+		state = "enabled"
+	}
+	fmt.Println(tui.SuccessStyle.Render(fmt.Sprintf("✓ MOTD %s for %s", state, strings.Join(shells, ", "))))
+
+	migrated, err := RemoveLegacy()
+	if err != nil {
+		return err
+	}
+	integration := shell.CheckStatus()
+	for _, sh := range shells {
+		if migrated[sh] {
+			fmt.Println(tui.InfoStyle.Render(fmt.Sprintf("ℹ Removed the old MOTD line from the %s startup file", sh)))
+		}
+		if enable && !integration[sh] && (target != "all" || migrated[sh]) {
+			fmt.Println(tui.InfoStyle.Render(fmt.Sprintf("ℹ %s shows the MOTD once the shell experience is on: bluefin-cli shell %s on", sh, sh)))
+		}
+	}
 	return nil
 }
 
-// Show displays the MOTD
+// RemoveLegacy removes the MOTD lines older versions added to shell startup files, which
+// ran the MOTD outside of 'bluefin-cli init'. It returns the shells it changed.
+func RemoveLegacy() (map[string]bool, error) {
+	removed := make(map[string]bool)
+	for _, sh := range shell.Shells {
+		path, err := shell.RcFile(sh)
+		if err != nil {
+			return removed, err
+		}
+		// Rewrite the target of a symlinked rc file, e.g. one managed by a dotfiles repo
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			path = resolved
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil || !hasLegacyLine(string(content)) {
+			continue
+		}
+
+		var lines []string
+		for _, line := range strings.Split(string(content), "\n") {
+			if !isLegacyLine(line) {
+				lines = append(lines, line)
+			}
+		}
+		output := strings.TrimRight(strings.Join(lines, "\n"), "\n") + "\n"
+		if err := env.WriteFileAtomic(path, []byte(output), info.Mode().Perm()); err != nil {
+			return removed, fmt.Errorf("failed to clean up %s: %w", path, err)
+		}
+		removed[sh] = true
+	}
+	return removed, nil
+}
+
+// isLegacyLine reports whether line is one older versions added, as opposed to the comment
+// heading the hook of 'bluefin-cli init'
+func isLegacyLine(line string) bool {
+	return strings.Contains(line, motdMarker) && !strings.Contains(line, motdMarker+" hook")
+}
+
+func hasLegacyLine(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if isLegacyLine(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// Show displays the MOTD, in color on a terminal
 func Show() error {
-	// Get OS info
-	info := getImageInfo()
+	return ShowFormat("")
+}
+
+// ShowFormat displays the MOTD in an output format, see ResolveFormat
+func ShowFormat(format string) error {
+	format, err := ResolveFormat(format)
+	if err != nil {
+		return err
+	}
 
 	// Get configuration (defaults if file missing)
-	config, err := loadConfig()
+	config, err := LoadConfig()
 	if err != nil {
 		// If error loading config, just use defaults
 		config = DefaultConfig()
 	}
 
-	// Get random tip
-	// First check if user has custom tips in the config directory
-	var tip string
-	if config.TipsDirectory != "" {
-		// Try to find custom tips
-		customTip := getRandomTipFromDir(config.TipsDirectory)
-		if customTip != "" {
-			tip = customTip
-		}
+	// Only an MOTD someone reads uses up a tip; JSON is for scripts that may poll it
+	data := collectData(config, format != FormatJSON)
+	if config.CheckOutdated {
+		refreshUpdatesIfStale(data.Updates)
 	}
-	
-	// Fallback to default tips if no custom tip found
-	if tip == "" {
-		tip = getRandomDefaultTip()
+	refreshDeploymentIfStale()
+	refreshFeedsIfStale(config)
+	if format == FormatJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(data)
 	}
 
-	// Format template
-	content := fmt.Sprintf(defaultTemplate, info.ImageName, info.ImageTag, tip)
-
-	// Render with glow if available
-	if glowPath, err := exec.LookPath("glow"); err == nil {
-		cmd := exec.Command(glowPath, "-s", "dark", "-w", "80", "-")
-		cmd.Stdin = strings.NewReader(content)
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		if err := cmd.Run(); err == nil {
-			return nil
+	content, err := renderTemplate(config.TemplateFile, data)
+	if err != nil {
+		// A broken custom template should not hide the MOTD
+		fmt.Fprintln(os.Stderr, tui.WarningStyle.Render(fmt.Sprintf("Warning: %v, using the default template", err)))
+		content, err = renderTemplate(DefaultTemplate, data)
+		if err != nil {
+			return err
 		}
 	}
 
-	// Fallback to plain text
-	fmt.Println(content)
+	if format == FormatMarkdown {
+		printMarkdown(data.System, content)
+		return nil
+	}
+
+	var rendered string
+	if format == FormatPlain {
+		rendered, err = RenderPlain(content, TerminalWidth())
+	} else {
+		rendered, err = Render(content, config, TerminalWidth())
+	}
+	if err != nil {
+		// Fall back to plain markdown
+		printMarkdown(data.System, content)
+		return nil
+	}
+	if len(data.System) > 0 {
+		fmt.Print("\n" + RenderWidgets(data.System, config, format == FormatANSI))
+	}
+	fmt.Print(rendered)
 	return nil
 }
 
+// printMarkdown prints the widgets as a markdown list above the MOTD content
+func printMarkdown(widgets []Widget, content string) {
+	for _, w := range widgets {
+		fmt.Printf("- **%s:** %s\n", w.Label, w.Value)
+	}
+	if len(widgets) > 0 {
+		fmt.Println()
+	}
+	fmt.Println(strings.TrimRight(content, "\n"))
+}
+
+func renderTemplate(nameOrPath string, data TemplateData) (string, error) {
+	text, err := LoadTemplate(nameOrPath)
+	if err != nil {
+		return "", err
+	}
+	return ExecuteTemplate(text, data)
+}
+
 // SetTheme sets the MOTD theme
 func SetTheme(theme string) error {
+	config, err := LoadConfig()
+	if err != nil {
+		config = DefaultConfig()
+	}
+
+	if !validTheme(theme, config.ThemesDirectory) {
+		return fmt.Errorf("unknown MOTD theme %s (available: %s)", theme, strings.Join(Themes(config.ThemesDirectory), ", "))
+	}
+	config.DefaultTheme = theme
+
+	if err := SaveConfig(config); err != nil {
+		return err
+	}
+
+	fmt.Println(tui.SuccessStyle.Render(fmt.Sprintf("✓ MOTD theme set to: %s", theme)))
+	return nil
+}
+
+// SaveConfig writes motd.json to the config directory, leaving out settings that hold the
+// policy or default value so later changes to those still apply
+func SaveConfig(config Config) error {
 	configDir, err := env.EnsureConfigDir()
 	if err != nil {
 		return err
 	}
-	configPath := filepath.Join(configDir, "motd.json")
 
-	config, err := loadConfig()
+	data, err := json.Marshal(config)
 	if err != nil {
-		config = DefaultConfig()
+		return err
 	}
 
-	config.DefaultTheme = theme
+	if err := checkLocked(data); err != nil {
+		return err
+	}
 
-	// Convert to JSON
-	data, err := json.MarshalIndent(config, "", "  ")
+	defaults, err := json.Marshal(DefaultConfig())
+	if err != nil {
+		return err
+	}
+	existing, err := readConfigFile()
 	if err != nil {
 		return err
 	}
-	
-	if err := os.WriteFile(configPath, data, 0644); err != nil {
+	pol, _ := policy.Load()
+	object, err := pol.Unmerge("motd", defaults, existing, data)
+	if err != nil {
+		return err
+	}
+	data, err = json.MarshalIndent(object, "", "  ")
+	if err != nil {
 		return err
 	}
 
-	fmt.Println(tui.SuccessStyle.Render(fmt.Sprintf("✓ MOTD theme set to: %s", theme)))
-	return nil
+	return env.WriteFileAtomic(filepath.Join(configDir, "motd.json"), data, 0644)
+}
+
+// CheckConfig returns the error SaveConfig would give for settings locked by policy
+func CheckConfig(config Config) error {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	return checkLocked(data)
+}
+
+func checkLocked(data []byte) error {
+	pol, _ := policy.Load()
+	return pol.CheckLocked("motd", data)
 }
 
 func DefaultConfig() Config {
 	return Config{
-		TipsDirectory:   "",
-		CheckOutdated:   "false",
-		ImageInfoFile:   "", // No longer used?
-		DefaultTheme:    "slate",
-		TemplateFile:    "",
-		ThemesDirectory: "",
+		TipsDirectory:    "",
+		CheckOutdated:    false,
+		ImageInfoFile:    "",
+		DefaultTheme:     "slate",
+		TemplateFile:     "",
+		ThemesDirectory:  "",
+		Widgets:          []string{},
+		Frequency:        FrequencyAlways,
+		SuppressIn:       []string{"tmux", "vscode"},
+		Feeds:            []string{},
+		MaxAnnouncements: 3,
 	}
 }
 
-func loadConfig() (Config, error) {
-	configDir, err := env.GetConfigDir()
+// LoadConfig reads motd.json over the policy settings and defaults. Locked policy keys
+// override the file. On error the defaults are returned along with it.
+func LoadConfig() (Config, error) {
+	data, err := readConfigFile()
 	if err != nil {
 		return DefaultConfig(), err
 	}
-	configPath := filepath.Join(configDir, "motd.json")
-	
-	var config Config
-	
-	data, err := os.ReadFile(configPath)
+
+	pol, _ := policy.Load()
+	merged, err := pol.Merge("motd", data)
 	if err != nil {
 		return DefaultConfig(), err
 	}
-	
-	if err := json.Unmarshal(data, &config); err != nil {
+
+	config := DefaultConfig()
+	if err := json.Unmarshal(merged, &config); err != nil {
 		return DefaultConfig(), err
 	}
-	
+
 	return config, nil
 }
 
-func getImageInfo() ImageInfo {
-	// Detect OS information
-	info := ImageInfo{
-		ImageFlavor:   "homebrew",
-		ImageVendor:   "bluefin-cli",
-		FedoraVersion: "N/A",
-	}
-
-	if runtime.GOOS == "darwin" {
-		info.ImageName = "macOS"
-		if output, err := exec.Command("sw_vers", "-productVersion").Output(); err == nil {
-			info.ImageTag = strings.TrimSpace(string(output))
-		}
-	} else if runtime.GOOS == "linux" {
-		if data, err := os.ReadFile("/etc/os-release"); err == nil {
-			lines := strings.Split(string(data), "\n")
-			for _, line := range lines {
-				if strings.HasPrefix(line, "NAME=") {
-					info.ImageName = strings.Trim(strings.TrimPrefix(line, "NAME="), `"`)
-				} else if strings.HasPrefix(line, "VERSION_ID=") {
-					info.ImageTag = strings.Trim(strings.TrimPrefix(line, "VERSION_ID="), `"`)
-				}
-			}
-		}
+func readConfigFile() ([]byte, error) {
+	configDir, err := env.GetConfigDir()
+	if err != nil {
+		return nil, err
 	}
 
-	if info.ImageName == "" {
-		info.ImageName = runtime.GOOS
-	}
-	if info.ImageTag == "" {
-		info.ImageTag = "unknown"
+	data, err := os.ReadFile(filepath.Join(configDir, "motd.json"))
+	if os.IsNotExist(err) {
+		return nil, nil
 	}
-
-	return info
+	return data, err
 }
 
-func getRandomTipFromDir(tipsDir string) string {
-	files, err := filepath.Glob(filepath.Join(tipsDir, "*.md"))
-	if err != nil || len(files) == 0 {
-		return ""
+// Effective returns every MOTD setting with its effective value and where it came from
+func Effective() ([]policy.Value, error) {
+	defaults, err := json.Marshal(DefaultConfig())
+	if err != nil {
+		return nil, err
 	}
-
-	rand.Seed(time.Now().UnixNano())
-	tipFile := files[rand.Intn(len(files))]
-
-	content, err := os.ReadFile(tipFile)
+	data, err := readConfigFile()
 	if err != nil {
-		return ""
+		return nil, err
 	}
 
-	return "💡 **Tip:** " + string(content)
+	pol, _ := policy.Load()
+	return pol.Resolve("motd", defaults, data)
 }
 
-func getRandomDefaultTip() string {
-	rand.Seed(time.Now().UnixNano())
-	tip := defaultTips[rand.Intn(len(defaultTips))]
-	return "💡 **Tip:** " + tip
+// ShellStatus is the MOTD state of one shell
+type ShellStatus struct {
+	// Enabled is the shell.json setting
+	Enabled bool `json:"enabled"`
+	// Integrated reports whether the startup file runs 'bluefin-cli init'
+	Integrated bool `json:"integrated"`
+	// Legacy reports whether the startup file still has a line older versions added
+	Legacy bool `json:"legacy"`
 }
 
-// CheckStatus returns whether MOTD is enabled for each shell
-// Now acts as a check for the legacy configuration
-func CheckStatus() map[string]bool {
-	status := make(map[string]bool)
-	shells := []string{"bash", "zsh", "fish"}
-
-	for _, shell := range shells {
-		var configFile string
-		switch shell {
-		case "bash":
-			configFile = filepath.Join(os.Getenv("HOME"), ".bashrc")
-		case "zsh":
-			configFile = filepath.Join(os.Getenv("HOME"), ".zshrc")
-		case "fish":
-			configFile = filepath.Join(os.Getenv("HOME"), ".config/fish/config.fish")
-		}
+// Shown reports whether new shells show the MOTD: through the init script when it is enabled,
+// or through a legacy line
+func (s ShellStatus) Shown() bool {
+	return s.Legacy || (s.Enabled && s.Integrated)
+}
+
+// ShellStatuses returns the MOTD state of each shell
+func ShellStatuses() map[string]ShellStatus {
+	statuses := make(map[string]ShellStatus)
+	integration := shell.CheckStatus()
 
-		content, err := os.ReadFile(configFile)
+	for _, sh := range shell.Shells {
+		cfg, err := shell.LoadConfig(sh)
 		if err != nil {
-			status[shell] = false
-			continue
+			cfg = shell.DefaultConfig(sh)
 		}
-
-		// Check for new marker (part of shell experience) OR old motd marker
-		status[shell] = strings.Contains(string(content), motdMarker) || strings.Contains(string(content), "# bluefin-cli shell-config")
+		status := ShellStatus{Enabled: cfg.MotdEnabled(sh), Integrated: integration[sh]}
+		if path, err := shell.RcFile(sh); err == nil {
+			if content, err := os.ReadFile(path); err == nil {
+				status.Legacy = hasLegacyLine(string(content))
+			}
+		}
+		statuses[sh] = status
 	}
+	return statuses
+}
 
+// CheckStatus returns whether new shells show the MOTD, for each shell
+func CheckStatus() map[string]bool {
+	status := make(map[string]bool)
+	for sh, s := range ShellStatuses() {
+		status[sh] = s.Shown()
+	}
 	return status
 }
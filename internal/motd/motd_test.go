@@ -1,27 +1,149 @@
 package motd
 
 import (
+	"encoding/json"
 	"io"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
+
+	"github.com/hanthor/bluefin-cli/internal/policy"
+	"github.com/hanthor/bluefin-cli/internal/shell"
 )
 
 func TestToggle(t *testing.T) {
-	// Toggle is deprecated and prints information, so just check it doesn't error
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	t.Setenv("HOMEBREW_PREFIX", "")
+
 	err := Toggle("bash", true)
 	if err != nil {
 		t.Errorf("Toggle() returned error: %v", err)
 	}
+
+	if err := Toggle("zsh", false); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := shell.LoadConfig("zsh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.MotdEnabled("bash") || cfg.MotdEnabled("zsh") || !cfg.MotdEnabled("fish") {
+		t.Errorf("Expected MOTD off for zsh only, got %v", *cfg)
+	}
+
+	// "all" replaces the per-shell settings
+	if err := Toggle("all", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := Toggle("all", true); err != nil {
+		t.Fatal(err)
+	}
+	cfg, _ = shell.LoadConfig("zsh")
+	for _, sh := range shell.Shells {
+		if !cfg.MotdEnabled(sh) {
+			t.Errorf("Expected MOTD on for %s, got %v", sh, *cfg)
+		}
+	}
+
+	if err := Toggle("tcsh", true); err == nil {
+		t.Error("Expected an unsupported shell to be rejected")
+	}
+}
+
+func TestToggleRemovesLegacyLines(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	t.Setenv("HOMEBREW_PREFIX", "")
+
+	// A private rc file kept in a dotfiles repo and linked into place
+	bashrc := filepath.Join(tmpHome, "dotfiles", "bashrc")
+	if err := os.MkdirAll(filepath.Dir(bashrc), 0755); err != nil {
+		t.Fatal(err)
+	}
+	rc := "export EDITOR=vim\nbluefin-cli motd show # bluefin-cli motd\neval \"$(bluefin-cli init bash)\" # bluefin-cli shell-config\n"
+	if err := os.WriteFile(bashrc, []byte(rc), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(bashrc, filepath.Join(tmpHome, ".bashrc")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Toggle("all", true); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(bashrc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "export EDITOR=vim\neval \"$(bluefin-cli init bash)\" # bluefin-cli shell-config\n"
+	if string(content) != want {
+		t.Errorf("Expected only the legacy line to be removed, got:\n%s", content)
+	}
+	if info, err := os.Stat(bashrc); err != nil {
+		t.Fatal(err)
+	} else if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the rc file to keep its mode, got %v", info.Mode())
+	}
+	if info, err := os.Lstat(filepath.Join(tmpHome, ".bashrc")); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Error("Expected .bashrc to stay a symlink")
+	}
+	if !CheckStatus()["bash"] {
+		t.Error("Expected the MOTD to stay enabled for bash through the init script")
+	}
+}
+
+func TestSaveConfigPolicy(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	t.Setenv("HOMEBREW_PREFIX", "")
+	t.Setenv(policy.PolicyEnv, "")
+	origSystemDir := policy.SystemDir
+	policy.SystemDir = t.TempDir()
+	defer func() { policy.SystemDir = origSystemDir }()
+
+	policyPath := filepath.Join(policy.SystemDir, "policy.json")
+	if err := os.WriteFile(policyPath, []byte(`{"settings": {"motd.tips-directory": "/opt/tips"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.DefaultTheme = "mocha"
+	if err := SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpHome, ".config", "bluefin-cli", "motd.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved map[string]any
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 1 || saved["default-theme"] != "mocha" {
+		t.Errorf("Expected only the changed theme to be saved, got %s", data)
+	}
+
+	// A later policy change still applies
+	if err := os.WriteFile(policyPath, []byte(`{"settings": {"motd.tips-directory": "/srv/tips"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, _ := LoadConfig(); cfg.TipsDirectory != "/srv/tips" || cfg.DefaultTheme != "mocha" {
+		t.Errorf("Expected the new policy default and the saved theme, got %+v", cfg)
+	}
 }
 
 func TestGetImageInfo(t *testing.T) {
 	// This function uses getImageInfo which is internal, but the test file is in 'package motd'
 	// so it should have access if it was exported or if test is in same package.
 	// Since getImageInfo is in motd.go and is package private 'getImageInfo', it is accessible here.
-	info := getImageInfo()
+	info := getImageInfo("")
 
 	if info.ImageName == "" {
 		t.Error("Expected ImageName to be set")
@@ -37,6 +159,7 @@ func TestCheckStatus(t *testing.T) {
 	tmpHome := t.TempDir()
 	os.Setenv("HOME", tmpHome)
 	defer os.Unsetenv("HOME")
+	t.Setenv("HOMEBREW_PREFIX", "")
 
 	// Manually inject legacy marker
 	bashrc := filepath.Join(tmpHome, ".bashrc")
@@ -52,6 +175,27 @@ func TestCheckStatus(t *testing.T) {
 	if status["zsh"] {
 		t.Error("Expected zsh MOTD to be disabled")
 	}
+
+	// With the init script, the MOTD follows shell.json
+	zshrc := filepath.Join(tmpHome, ".zshrc")
+	if err := os.WriteFile(zshrc, []byte(`eval "$(bluefin-cli init zsh)" # bluefin-cli shell-config`+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write zshrc: %v", err)
+	}
+	if !CheckStatus()["zsh"] {
+		t.Error("Expected zsh MOTD to be enabled")
+	}
+	cfg := shell.DefaultConfig("zsh")
+	(*cfg)[shell.MotdKey("zsh")] = false
+	if err := shell.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	statuses := ShellStatuses()
+	if st := statuses["zsh"]; st.Shown() || st.Enabled || !st.Integrated {
+		t.Errorf("Expected zsh MOTD to be disabled in shell.json, got %+v", st)
+	}
+	if st := statuses["bash"]; !st.Shown() || !st.Legacy {
+		t.Errorf("Expected bash MOTD to be shown by the legacy line, got %+v", st)
+	}
 }
 
 func TestShow(t *testing.T) {
@@ -99,6 +243,72 @@ func TestShow(t *testing.T) {
 	}
 }
 
+func TestShowFormat(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	t.Setenv("HOMEBREW_PREFIX", "")
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	capture := func(format string) string {
+		t.Helper()
+		oldStdout := os.Stdout
+		r, w, _ := os.Pipe()
+		os.Stdout = w
+		err := ShowFormat(format)
+		w.Close()
+		os.Stdout = oldStdout
+		if err != nil {
+			t.Fatalf("ShowFormat(%q) returned error: %v", format, err)
+		}
+		out, _ := io.ReadAll(r)
+		return string(out)
+	}
+
+	var data TemplateData
+	if err := json.Unmarshal([]byte(capture(FormatJSON)), &data); err != nil {
+		t.Fatalf("Expected JSON output: %v", err)
+	}
+	if data.Hostname == "" || data.Tip == "" {
+		t.Errorf("Expected the MOTD data, got %+v", data)
+	}
+	// Scripts polling the JSON don't use up tips
+	capture(FormatJSON)
+	if shown := ShownTips(); len(shown) != 0 {
+		t.Errorf("Expected JSON output to leave the tip rotation alone, got %v", shown)
+	}
+
+	if out := capture(FormatMarkdown); !strings.Contains(out, "# ") || !strings.Contains(out, "| ------- |") {
+		t.Errorf("Expected the template markdown, got:\n%s", out)
+	}
+
+	// Stdout is a pipe, so the default is plain
+	if out := capture(""); out != stripAnsi(out) || !strings.Contains(out, "Welcome to Bluefin CLI") {
+		t.Errorf("Expected plain output, got:\n%q", out)
+	}
+	if shown := ShownTips(); len(shown) != 2 {
+		t.Errorf("Expected the markdown and plain MOTDs to advance the tip rotation, got %v", shown)
+	}
+	if out := capture(FormatANSI); out == stripAnsi(out) {
+		t.Error("Expected colored output with --format ansi")
+	}
+
+	// When the theme can't be loaded, the markdown fallback keeps the widgets
+	themesDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(themesDir, "broken.json"), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultConfig()
+	cfg.ThemesDirectory = themesDir
+	cfg.DefaultTheme = "broken"
+	cfg.Widgets = []string{"disk"}
+	if err := SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if out := capture(FormatANSI); !strings.Contains(out, "- **") || !strings.Contains(out, "# ") {
+		t.Errorf("Expected the widgets and markdown in the fallback, got:\n%s", out)
+	}
+}
+
 func stripAnsi(str string) string {
 	const ansi = "[\u001B\u009B][[\\]()#;?]*(?:(?:(?:[a-zA-Z\\d]*(?:;[a-zA-Z\\d]*)*)?\u0007)|(?:(?:\\d{1,4}(?:;\\d{0,4})*)?[\\dA-PRZcf-ntqry=><~]))"
 	var re = regexp.MustCompile(ansi)
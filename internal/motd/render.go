@@ -0,0 +1,183 @@
+package motd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/ansi"
+	"github.com/charmbracelet/glamour/styles"
+	"golang.org/x/term"
+)
+
+const (
+	defaultWidth = 80
+	maxWidth     = 100
+)
+
+// Output formats of the MOTD
+const (
+	// FormatPlain is the rendered MOTD without colors or markdown markup
+	FormatPlain = "plain"
+	// FormatMarkdown is the template output before rendering
+	FormatMarkdown = "markdown"
+	// FormatANSI is the rendered MOTD with the theme's colors
+	FormatANSI = "ansi"
+	// FormatJSON is the template data, for status bars and other tools
+	FormatJSON = "json"
+)
+
+// Formats lists the MOTD output formats
+var Formats = []string{FormatPlain, FormatMarkdown, FormatANSI, FormatJSON}
+
+// isTerminal reports whether stdout is a terminal, replaced in tests
+var isTerminal = func() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// ResolveFormat checks an output format. An empty format is ansi on a terminal, and plain
+// when stdout is redirected or NO_COLOR is set.
+func ResolveFormat(format string) (string, error) {
+	switch format {
+	case "":
+		if os.Getenv("NO_COLOR") != "" || !isTerminal() {
+			return FormatPlain, nil
+		}
+		return FormatANSI, nil
+	case FormatPlain, FormatMarkdown, FormatANSI, FormatJSON:
+		return format, nil
+	}
+	return "", fmt.Errorf("invalid MOTD format %q: use %s", format, strings.Join(Formats, ", "))
+}
+
+// builtinThemes are the MOTD themes rendered without a styles file
+var builtinThemes = map[string]func() ansi.StyleConfig{
+	"slate":   slateStyle,
+	"dark":    func() ansi.StyleConfig { return styles.DarkStyleConfig },
+	"light":   func() ansi.StyleConfig { return styles.LightStyleConfig },
+	"dracula": func() ansi.StyleConfig { return styles.DraculaStyleConfig },
+	"pink":    func() ansi.StyleConfig { return styles.PinkStyleConfig },
+}
+
+// slateStyle is the dark style in Bluefin's blue-grey palette
+func slateStyle() ansi.StyleConfig {
+	s := styles.DarkStyleConfig
+	color := func(c string) *string { return &c }
+
+	s.Document.Color = color("#CBD5E1")
+	s.Heading.Color = color("#7DD3FC")
+	s.H1.Color = color("#F8FAFC")
+	s.H1.BackgroundColor = color("#334155")
+	s.Strong.Color = color("#E2E8F0")
+	s.Link.Color = color("#38BDF8")
+	s.LinkText.Color = color("#7DD3FC")
+	s.Code.Color = color("#FDBA74")
+	s.Code.BackgroundColor = color("#1E293B")
+	return s
+}
+
+// Themes lists the built-in themes and the JSON styles in themesDir, sorted by name
+func Themes(themesDir string) []string {
+	seen := make(map[string]bool)
+	for name := range builtinThemes {
+		seen[name] = true
+	}
+	if themesDir != "" {
+		matches, _ := filepath.Glob(filepath.Join(themesDir, "*.json"))
+		for _, m := range matches {
+			seen[strings.TrimSuffix(filepath.Base(m), ".json")] = true
+		}
+	}
+
+	var names []string
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// themeStyle returns the glamour style of a theme. A JSON style in themesDir (in glamour's
+// format) takes precedence over a built-in theme of the same name; unknown themes use slate.
+func themeStyle(theme, themesDir string) (ansi.StyleConfig, error) {
+	if themesDir != "" {
+		path := filepath.Join(themesDir, theme+".json")
+		if data, err := os.ReadFile(path); err == nil {
+			var style ansi.StyleConfig
+			if err := json.Unmarshal(data, &style); err != nil {
+				return ansi.StyleConfig{}, fmt.Errorf("failed to parse %s: %w", path, err)
+			}
+			return style, nil
+		}
+	}
+	if style, ok := builtinThemes[theme]; ok {
+		return style(), nil
+	}
+	return slateStyle(), nil
+}
+
+// TerminalWidth returns the width of the terminal on stdout, capped so long lines stay
+// readable, or a default when stdout is not a terminal
+func TerminalWidth() int {
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 {
+		return defaultWidth
+	}
+	return min(width, maxWidth)
+}
+
+// Render renders markdown with the configured theme, wrapped to width
+func Render(markdown string, config Config, width int) (string, error) {
+	style, err := themeStyle(config.DefaultTheme, config.ThemesDirectory)
+	if err != nil {
+		return "", fmt.Errorf("failed to load MOTD theme %s: %w", config.DefaultTheme, err)
+	}
+	out, err := renderStyle(markdown, style, width)
+	if err != nil {
+		return "", fmt.Errorf("failed to load MOTD theme %s: %w", config.DefaultTheme, err)
+	}
+	return out, nil
+}
+
+// RenderPlain renders markdown as text without colors or markup, wrapped to width
+func RenderPlain(markdown string, width int) (string, error) {
+	style := styles.NoTTYStyleConfig
+	style.Strong = ansi.StylePrimitive{}
+	style.Emph = ansi.StylePrimitive{}
+	out, err := renderStyle(markdown, style, width)
+	if err != nil {
+		return "", err
+	}
+
+	// The renderer pads every line to the wrap width
+	lines := strings.Split(out, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func renderStyle(markdown string, style ansi.StyleConfig, width int) (string, error) {
+	renderer, err := glamour.NewTermRenderer(
+		glamour.WithStyles(style),
+		glamour.WithWordWrap(width),
+		glamour.WithEmoji(),
+	)
+	if err != nil {
+		return "", err
+	}
+	return renderer.Render(markdown)
+}
+
+func validTheme(theme, themesDir string) bool {
+	for _, name := range Themes(themesDir) {
+		if name == theme {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package motd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderBuiltinThemes(t *testing.T) {
+	for theme := range builtinThemes {
+		t.Run(theme, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.DefaultTheme = theme
+
+			out, err := Render("# Welcome\n\nUse `brew search` to find packages", cfg, 60)
+			if err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+			plain := stripAnsi(out)
+			if !strings.Contains(plain, "Welcome") || !strings.Contains(plain, "brew search") {
+				t.Errorf("Unexpected output:\n%s", plain)
+			}
+		})
+	}
+}
+
+func TestRenderWraps(t *testing.T) {
+	out, err := Render(strings.Repeat("word ", 40), DefaultConfig(), 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(stripAnsi(out), "\n") {
+		if len([]rune(strings.TrimRight(line, " "))) > 40 {
+			t.Errorf("Line exceeds width 40: %q", line)
+		}
+	}
+}
+
+func TestThemesDirectory(t *testing.T) {
+	dir := t.TempDir()
+	style := `{"document": {}, "h1": {"prefix": ">> "}, "heading": {"block_suffix": "\n"}}`
+	if err := os.WriteFile(filepath.Join(dir, "acme.json"), []byte(style), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	themes := Themes(dir)
+	if !validTheme("acme", dir) || !validTheme("slate", dir) {
+		t.Errorf("Expected user and built-in themes, got %v", themes)
+	}
+	if validTheme("nope", dir) {
+		t.Error("Expected unknown theme to be rejected")
+	}
+
+	cfg := DefaultConfig()
+	cfg.DefaultTheme = "acme"
+	cfg.ThemesDirectory = dir
+	out, err := Render("# Welcome", cfg, 60)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(stripAnsi(out), ">> Welcome") {
+		t.Errorf("Expected the user style's heading prefix, got:\n%s", stripAnsi(out))
+	}
+}
+
+func TestSetThemeRejectsUnknown(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("HOMEBREW_PREFIX", "")
+
+	if err := SetTheme("neon"); err == nil {
+		t.Error("Expected an unknown theme to be rejected")
+	}
+	if err := SetTheme("dracula"); err != nil {
+		t.Fatalf("SetTheme failed: %v", err)
+	}
+	cfg, _ := LoadConfig()
+	if cfg.DefaultTheme != "dracula" {
+		t.Errorf("Expected dracula, got %s", cfg.DefaultTheme)
+	}
+}
+
+func TestRenderPlain(t *testing.T) {
+	out, err := RenderPlain("# Welcome\n\n💡 **Tip:** use `brew search` to *find* packages", 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != stripAnsi(out) {
+		t.Errorf("Expected no escape sequences, got %q", out)
+	}
+	if !strings.Contains(out, "💡 Tip: use brew search to find packages") {
+		t.Errorf("Expected the text without markup, got:\n%s", out)
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasSuffix(line, " ") {
+			t.Errorf("Expected no trailing spaces, got %q", line)
+		}
+	}
+}
+
+func TestResolveFormat(t *testing.T) {
+	origIsTerminal := isTerminal
+	defer func() { isTerminal = origIsTerminal }()
+
+	tests := []struct {
+		format   string
+		terminal bool
+		noColor  string
+		want     string
+	}{
+		{"", true, "", FormatANSI},
+		{"", false, "", FormatPlain},
+		{"", true, "1", FormatPlain},
+		{FormatANSI, false, "1", FormatANSI},
+		{FormatJSON, true, "", FormatJSON},
+		{FormatMarkdown, true, "", FormatMarkdown},
+	}
+	for _, tt := range tests {
+		isTerminal = func() bool { return tt.terminal }
+		t.Setenv("NO_COLOR", tt.noColor)
+		got, err := ResolveFormat(tt.format)
+		if err != nil || got != tt.want {
+			t.Errorf("ResolveFormat(%q) on terminal=%v NO_COLOR=%q = %q, %v, want %q", tt.format, tt.terminal, tt.noColor, got, err, tt.want)
+		}
+	}
+
+	if _, err := ResolveFormat("html"); err == nil {
+		t.Error("Expected an unknown format to be rejected")
+	}
+}
@@ -0,0 +1,379 @@
+package motd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+	"github.com/muesli/termenv"
+)
+
+// Roots of the kernel and runtime file systems, replaced in tests with fixture trees
+var (
+	procRoot = "/proc"
+	sysRoot  = "/sys"
+	runRoot  = "/run"
+)
+
+// statfs reports file system usage, replaced in tests
+var statfs = syscall.Statfs
+
+// interfaceAddrs lists the addresses of each network interface that is up, replaced in tests
+var interfaceAddrs = func() (map[string][]net.Addr, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	addrs := make(map[string][]net.Addr)
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		if a, err := iface.Addrs(); err == nil && len(a) > 0 {
+			addrs[iface.Name] = a
+		}
+	}
+	return addrs, nil
+}
+
+// Widget is one line of the system information section, e.g. "Memory  5.2 GiB / 16 GiB (33%)"
+type Widget struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// widgetCollectors gather each widget, in display order. A widget that cannot be read on
+// this machine returns nothing and is left out.
+var widgetCollectors = []struct {
+	name    string
+	collect func() []Widget
+}{
+	{"uptime", uptimeWidget},
+	{"load", loadWidget},
+	{"memory", memoryWidget},
+	{"disk", diskWidgets},
+	{"ip", ipWidget},
+	{"users", usersWidget},
+	{"failed-units", failedUnitsWidget},
+}
+
+// WidgetNames lists the system information widgets that can be enabled in motd.json
+func WidgetNames() []string {
+	names := make([]string, len(widgetCollectors))
+	for i, c := range widgetCollectors {
+		names[i] = c.name
+	}
+	return names
+}
+
+// CollectWidgets gathers the enabled widgets in display order
+func CollectWidgets(enabled []string) []Widget {
+	on := make(map[string]bool)
+	for _, name := range enabled {
+		on[name] = true
+	}
+
+	var widgets []Widget
+	for _, c := range widgetCollectors {
+		if on[c.name] {
+			widgets = append(widgets, c.collect()...)
+		}
+	}
+	return widgets
+}
+
+// RenderWidgets lays the widgets out in a label and a value column, colored like the
+// headings and text of the MOTD theme when color is set
+func RenderWidgets(widgets []Widget, config Config, color bool) string {
+	if len(widgets) == 0 {
+		return ""
+	}
+	style, err := themeStyle(config.DefaultTheme, config.ThemesDirectory)
+	if err != nil {
+		style = slateStyle()
+	}
+
+	// The MOTD is colored the same wherever stdout goes, like the glamour output below it
+	renderer := lipgloss.NewRenderer(io.Discard)
+	if color {
+		renderer.SetColorProfile(termenv.TrueColor)
+	}
+	label := renderer.NewStyle().Bold(true)
+	if c := style.Heading.Color; c != nil {
+		label = label.Foreground(lipgloss.Color(*c))
+	}
+	value := renderer.NewStyle()
+	if c := style.Document.Color; c != nil {
+		value = value.Foreground(lipgloss.Color(*c))
+	}
+	margin := 2
+	if m := style.Document.Margin; m != nil {
+		margin = int(*m)
+	}
+
+	width := 0
+	for _, w := range widgets {
+		width = max(width, lipgloss.Width(w.Label))
+	}
+
+	var b strings.Builder
+	for _, w := range widgets {
+		fmt.Fprintf(&b, "%s%s  %s\n", strings.Repeat(" ", margin), label.Render(fmt.Sprintf("%-*s", width, w.Label)), value.Render(w.Value))
+	}
+	return b.String()
+}
+
+func uptimeWidget() []Widget {
+	up, ok := uptime()
+	if !ok {
+		return nil
+	}
+	return []Widget{{Name: "uptime", Label: "Uptime", Value: formatUptime(up)}}
+}
+
+// loadWidget reads the 1, 5 and 15 minute load averages and the number of online CPUs
+func loadWidget() []Widget {
+	raw, err := os.ReadFile(filepath.Join(procRoot, "loadavg"))
+	if err != nil {
+		return nil
+	}
+	fields := strings.Fields(string(raw))
+	if len(fields) < 3 {
+		return nil
+	}
+
+	value := strings.Join(fields[:3], " ")
+	if cpus := onlineCPUs(); cpus > 0 {
+		value += fmt.Sprintf(" (%d %s)", cpus, plural("CPU", cpus))
+	}
+	return []Widget{{Name: "load", Label: "Load", Value: value}}
+}
+
+// onlineCPUs counts the CPUs in /sys/devices/system/cpu/online, e.g. "0-3,6" is 5
+func onlineCPUs() int {
+	raw, err := os.ReadFile(filepath.Join(sysRoot, "devices", "system", "cpu", "online"))
+	if err != nil {
+		return 0
+	}
+	count := 0
+	for _, part := range strings.Split(strings.TrimSpace(string(raw)), ",") {
+		first, last, isRange := strings.Cut(part, "-")
+		lo, err := strconv.Atoi(first)
+		if err != nil {
+			return 0
+		}
+		hi := lo
+		if isRange {
+			if hi, err = strconv.Atoi(last); err != nil {
+				return 0
+			}
+		}
+		count += hi - lo + 1
+	}
+	return count
+}
+
+// memoryWidget reads total and available memory from /proc/meminfo
+func memoryWidget() []Widget {
+	f, err := os.Open(filepath.Join(procRoot, "meminfo"))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	kB := make(map[string]uint64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// "MemTotal:       16287300 kB"
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 {
+			if n, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+				kB[strings.TrimSuffix(fields[0], ":")] = n
+			}
+		}
+	}
+
+	total, available := kB["MemTotal"]*1024, kB["MemAvailable"]*1024
+	if total == 0 || available > total {
+		return nil
+	}
+	return []Widget{{Name: "memory", Label: "Memory", Value: usage(total-available, total)}}
+}
+
+// diskWidgets reports usage of / and of $HOME when it is on a different file system
+func diskWidgets() []Widget {
+	var widgets []Widget
+	var root syscall.Statfs_t
+	if err := statfs("/", &root); err == nil && root.Blocks > 0 {
+		widgets = append(widgets, Widget{Name: "disk", Label: "Disk (/)", Value: diskUsage(root)})
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return widgets
+	}
+	var st syscall.Statfs_t
+	if err := statfs(home, &st); err != nil || st.Blocks == 0 {
+		return widgets
+	}
+	// Some file systems report no ID, so a different size also means a different file system
+	if len(widgets) == 0 || st.Fsid != root.Fsid || st.Blocks != root.Blocks {
+		widgets = append(widgets, Widget{Name: "disk", Label: "Disk (~)", Value: diskUsage(st)})
+	}
+	return widgets
+}
+
+// diskUsage counts reserved blocks as free, like df
+func diskUsage(st syscall.Statfs_t) string {
+	bsize := uint64(st.Bsize)
+	used := (st.Blocks - st.Bfree) * bsize
+	return usage(used, used+st.Bavail*bsize)
+}
+
+// usage formats used and total bytes, e.g. "5.2 GiB / 16 GiB (33%)"
+func usage(used, total uint64) string {
+	return fmt.Sprintf("%s / %s (%d%%)", humanize.IBytes(used), humanize.IBytes(total), used*100/total)
+}
+
+// ipWidget lists the addresses of the interfaces with a default route in /proc/net/route,
+// or of every interface that is up where the routing table can't be read
+func ipWidget() []Widget {
+	addrs, err := interfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	routed := defaultRouteInterfaces()
+
+	var names []string
+	for name := range addrs {
+		if routed == nil || routed[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var parts []string
+	for _, name := range names {
+		for _, addr := range addrs[name] {
+			ipnet, ok := addr.(*net.IPNet)
+			if !ok || !ipnet.IP.IsGlobalUnicast() {
+				continue
+			}
+			parts = append(parts, fmt.Sprintf("%s (%s)", ipnet.IP, name))
+		}
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	return []Widget{{Name: "ip", Label: "IP", Value: strings.Join(parts, ", ")}}
+}
+
+func defaultRouteInterfaces() map[string]bool {
+	f, err := os.Open(filepath.Join(procRoot, "net", "route"))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	routed := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// "Iface Destination Gateway ..." with the default route's destination 00000000
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 1 && fields[1] == "00000000" {
+			routed[fields[0]] = true
+		}
+	}
+	return routed
+}
+
+// usersWidget lists users with a systemd-logind session, falling back to who
+func usersWidget() []Widget {
+	users := make(map[string]bool)
+	sessions, _ := filepath.Glob(filepath.Join(runRoot, "systemd", "sessions", "*"))
+	for _, path := range sessions {
+		if strings.HasSuffix(path, ".ref") {
+			continue
+		}
+		fields := readEnvFile(path)
+		if fields["USER"] != "" && strings.HasPrefix(fields["CLASS"], "user") {
+			users[fields["USER"]] = true
+		}
+	}
+
+	if len(sessions) == 0 {
+		out, err := execCommand("who").Output()
+		if err != nil {
+			return nil
+		}
+		for _, line := range strings.Split(string(out), "\n") {
+			if fields := strings.Fields(line); len(fields) > 0 {
+				users[fields[0]] = true
+			}
+		}
+	}
+
+	if len(users) == 0 {
+		return nil
+	}
+	var names []string
+	for name := range users {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return []Widget{{Name: "users", Label: "Users", Value: strings.Join(names, ", ")}}
+}
+
+// readEnvFile parses KEY=value lines, the format of systemd's runtime state files
+func readEnvFile(path string) map[string]string {
+	fields := make(map[string]string)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fields
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if key, value, ok := strings.Cut(line, "="); ok {
+			fields[key] = value
+		}
+	}
+	return fields
+}
+
+// failedUnitsWidget lists failed system and user units
+func failedUnitsWidget() []Widget {
+	if _, err := lookPath("systemctl"); err != nil {
+		return nil
+	}
+
+	var failed []string
+	for _, scope := range []string{"--system", "--user"} {
+		out, err := execCommand("systemctl", scope, "list-units", "--state=failed", "--plain", "--no-legend", "--no-pager").Output()
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(out), "\n") {
+			if fields := strings.Fields(line); len(fields) > 0 {
+				failed = append(failed, fields[0])
+			}
+		}
+	}
+
+	value := "none"
+	switch {
+	case len(failed) > 3:
+		value = fmt.Sprintf("%s and %d more", strings.Join(failed[:3], ", "), len(failed)-3)
+	case len(failed) > 0:
+		value = strings.Join(failed, ", ")
+	}
+	return []Widget{{Name: "failed-units", Label: "Failed units", Value: value}}
+}
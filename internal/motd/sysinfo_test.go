@@ -0,0 +1,180 @@
+package motd
+
+import (
+	"errors"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// fixtureRoot points procRoot, sysRoot and runRoot at a temporary tree holding files
+func fixtureRoot(t *testing.T, files map[string]string) {
+	t.Helper()
+	root := t.TempDir()
+	for path, content := range files {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	origProc, origSys, origRun := procRoot, sysRoot, runRoot
+	t.Cleanup(func() { procRoot, sysRoot, runRoot = origProc, origSys, origRun })
+	procRoot = filepath.Join(root, "proc")
+	sysRoot = filepath.Join(root, "sys")
+	runRoot = filepath.Join(root, "run")
+}
+
+func TestProcWidgets(t *testing.T) {
+	fixtureRoot(t, map[string]string{
+		"proc/uptime":                   "183723.45 1423412.10\n",
+		"proc/loadavg":                  "0.52 0.48 0.40 2/1204 48213\n",
+		"proc/meminfo":                  "MemTotal:       16777216 kB\nMemFree:         1048576 kB\nMemAvailable:    8388608 kB\nBuffers:          204800 kB\n",
+		"sys/devices/system/cpu/online": "0-3,6\n",
+		"run/systemd/sessions/2":        "UID=1000\nUSER=alice\nACTIVE=1\nSTATE=active\nCLASS=user\n",
+		"run/systemd/sessions/5":        "UID=1001\nUSER=bob\nCLASS=user\n",
+		"run/systemd/sessions/5.ref":    "",
+		"run/systemd/sessions/c1":       "UID=42\nUSER=gdm\nCLASS=greeter\n",
+	})
+
+	widgets := CollectWidgets([]string{"users", "memory", "load", "uptime"})
+	want := []Widget{
+		{Name: "uptime", Label: "Uptime", Value: "2 days 3 hours"},
+		{Name: "load", Label: "Load", Value: "0.52 0.48 0.40 (5 CPUs)"},
+		{Name: "memory", Label: "Memory", Value: "8.0 GiB / 16 GiB (50%)"},
+		{Name: "users", Label: "Users", Value: "alice, bob"},
+	}
+	if len(widgets) != len(want) {
+		t.Fatalf("CollectWidgets() = %+v, want %+v", widgets, want)
+	}
+	for i := range want {
+		if widgets[i] != want[i] {
+			t.Errorf("Widget %d = %+v, want %+v", i, widgets[i], want[i])
+		}
+	}
+}
+
+func TestWidgetsMissingFiles(t *testing.T) {
+	fixtureRoot(t, nil)
+	origExecCommand := execCommand
+	defer func() { execCommand = origExecCommand }()
+	execCommand = func(name string, arg ...string) *exec.Cmd { return exec.Command("false") }
+
+	if widgets := CollectWidgets([]string{"uptime", "load", "memory", "users"}); len(widgets) != 0 {
+		t.Errorf("Expected unreadable widgets to be left out, got %+v", widgets)
+	}
+}
+
+func TestDiskWidgets(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	origStatfs := statfs
+	defer func() { statfs = origStatfs }()
+
+	sameFS := true
+	statfs = func(path string, st *syscall.Statfs_t) error {
+		*st = syscall.Statfs_t{Bsize: 4096, Blocks: 1000, Bfree: 300, Bavail: 200}
+		if path == home && !sameFS {
+			st.Blocks, st.Bfree, st.Bavail = 2000, 1000, 1000
+		}
+		return nil
+	}
+
+	widgets := diskWidgets()
+	if len(widgets) != 1 || widgets[0].Label != "Disk (/)" || widgets[0].Value != "2.7 MiB / 3.5 MiB (77%)" {
+		t.Errorf("Unexpected widgets on one file system: %+v", widgets)
+	}
+
+	sameFS = false
+	widgets = diskWidgets()
+	if len(widgets) != 2 || widgets[1].Label != "Disk (~)" || widgets[1].Value != "3.9 MiB / 7.8 MiB (50%)" {
+		t.Errorf("Unexpected widgets with a separate home: %+v", widgets)
+	}
+}
+
+func TestIPWidget(t *testing.T) {
+	fixtureRoot(t, map[string]string{
+		"proc/net/route": "Iface\tDestination\tGateway\tFlags\nwlp2s0\t00000000\t0101A8C0\t0003\nwlp2s0\t0001A8C0\t00000000\t0001\ndocker0\t000011AC\t00000000\t0001\n",
+	})
+	origAddrs := interfaceAddrs
+	defer func() { interfaceAddrs = origAddrs }()
+
+	cidr := func(s string) net.Addr {
+		ip, ipnet, err := net.ParseCIDR(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ipnet.IP = ip
+		return ipnet
+	}
+	interfaceAddrs = func() (map[string][]net.Addr, error) {
+		return map[string][]net.Addr{
+			"wlp2s0":  {cidr("192.168.1.20/24"), cidr("fe80::1/64"), cidr("2001:db8::20/64")},
+			"docker0": {cidr("172.17.0.1/16")},
+		}, nil
+	}
+
+	widgets := ipWidget()
+	if len(widgets) != 1 || widgets[0].Value != "192.168.1.20 (wlp2s0), 2001:db8::20 (wlp2s0)" {
+		t.Errorf("Unexpected IP widget %+v", widgets)
+	}
+
+	interfaceAddrs = func() (map[string][]net.Addr, error) { return nil, errors.New("no network") }
+	if widgets := ipWidget(); widgets != nil {
+		t.Errorf("Expected no IP widget, got %+v", widgets)
+	}
+}
+
+func TestFailedUnitsWidget(t *testing.T) {
+	origLookPath, origExecCommand := lookPath, execCommand
+	defer func() { lookPath, execCommand = origLookPath, origExecCommand }()
+
+	lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	execCommand = func(name string, arg ...string) *exec.Cmd {
+		if arg[0] == "--system" {
+			return exec.Command("printf", "a.service loaded failed failed A\nb.service loaded failed failed B\nc.mount loaded failed failed C\n")
+		}
+		return exec.Command("printf", "d.service loaded failed failed D\n")
+	}
+	widgets := failedUnitsWidget()
+	if len(widgets) != 1 || widgets[0].Value != "a.service, b.service, c.mount and 1 more" {
+		t.Errorf("Unexpected failed units widget %+v", widgets)
+	}
+
+	execCommand = func(name string, arg ...string) *exec.Cmd { return exec.Command("true") }
+	if widgets := failedUnitsWidget(); widgets[0].Value != "none" {
+		t.Errorf("Expected no failed units, got %+v", widgets)
+	}
+}
+
+func TestRenderWidgets(t *testing.T) {
+	widgets := []Widget{
+		{Name: "uptime", Label: "Uptime", Value: "2 days"},
+		{Name: "failed-units", Label: "Failed units", Value: "none"},
+	}
+	colored := RenderWidgets(widgets, DefaultConfig(), true)
+	out := stripAnsi(colored)
+	want := "  Uptime        2 days\n  Failed units  none\n"
+	if out != want {
+		t.Errorf("RenderWidgets() = %q, want %q", out, want)
+	}
+	if colored == out {
+		t.Error("Expected colored widgets")
+	}
+	if plain := RenderWidgets(widgets, DefaultConfig(), false); plain != want {
+		t.Errorf("RenderWidgets() without color = %q, want %q", plain, want)
+	}
+	if RenderWidgets(nil, DefaultConfig(), true) != "" {
+		t.Error("Expected no output without widgets")
+	}
+	if !strings.Contains(strings.Join(WidgetNames(), ","), "failed-units") {
+		t.Errorf("Unexpected widget names %v", WidgetNames())
+	}
+}
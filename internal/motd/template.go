@@ -0,0 +1,257 @@
+package motd
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/hanthor/bluefin-cli/internal/shell"
+)
+
+//go:embed templates/*.md
+var templates embed.FS
+
+// DefaultTemplate is the shipped template used when TemplateFile is not set
+const DefaultTemplate = "default"
+
+// TemplateData is what MOTD templates are executed with. Templates are Go text/template
+// files producing markdown, e.g. "{{.User}}@{{.Hostname}}" or "{{.Date.Format "Jan 2"}}".
+type TemplateData struct {
+	// Image describes the OS image, e.g. {{.Image.ImageName}}:{{.Image.ImageTag}}
+	Image ImageInfo `json:"image"`
+	// Deployment is the booted and staged image on rpm-ostree and bootc systems, or nil
+	Deployment *Deployment `json:"deployment,omitempty"`
+	// Hostname is the machine's host name
+	Hostname string `json:"hostname"`
+	// User is the login name of the current user
+	User string `json:"user"`
+	// Uptime is the time since boot, e.g. "3 days 4 hours", or "" when unknown
+	Uptime string `json:"uptime"`
+	// Announcements are the unread items of the feeds in motd.json, newest first
+	Announcements []Announcement `json:"announcements,omitempty"`
+	// Tip is the next tip of the rotation in markdown, without a "Tip:" prefix
+	Tip string `json:"tip"`
+	// System holds the system information widgets enabled in motd.json, e.g. memory and disk
+	System []Widget `json:"system,omitempty"`
+	// Tools lists the shell experience tools, whether they are enabled and installed
+	Tools []ToolStatus `json:"tools"`
+	// Updates counts outdated packages from the last background refresh; nil unless
+	// check-outdated is enabled and a refresh has finished
+	Updates *Updates `json:"updates,omitempty"`
+	// Date is the current time
+	Date time.Time `json:"date"`
+}
+
+// ToolStatus is the state of one shell experience tool
+type ToolStatus struct {
+	Name      string `json:"name"`
+	Enabled   bool   `json:"enabled"`
+	Installed bool   `json:"installed"`
+}
+
+// ShippedTemplates lists the names of the templates built into bluefin-cli
+func ShippedTemplates() []string {
+	entries, _ := templates.ReadDir("templates")
+	var names []string
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), ".md"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadTemplate reads a template by shipped name or file path, defaulting to DefaultTemplate
+func LoadTemplate(nameOrPath string) (string, error) {
+	if nameOrPath == "" {
+		nameOrPath = DefaultTemplate
+	}
+	if !strings.ContainsAny(nameOrPath, `/\`) {
+		if data, err := templates.ReadFile("templates/" + nameOrPath + ".md"); err == nil {
+			return string(data), nil
+		}
+	}
+
+	data, err := os.ReadFile(nameOrPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read MOTD template: %w", err)
+	}
+	return string(data), nil
+}
+
+// ExecuteTemplate executes a template with data, returning markdown. Unknown fields are errors.
+func ExecuteTemplate(text string, data TemplateData) (string, error) {
+	tmpl, err := template.New("motd").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid MOTD template: %w", err)
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("invalid MOTD template: %w", err)
+	}
+	return out.String(), nil
+}
+
+// ValidateTemplate checks that a template parses and executes against sample data
+func ValidateTemplate(text string) error {
+	_, err := ExecuteTemplate(text, SampleData())
+	return err
+}
+
+// SampleData is example template data with every field set, for validating templates
+func SampleData() TemplateData {
+	return TemplateData{
+		Image: ImageInfo{ImageName: "Bluefin", ImageTag: "stable", ImageFlavor: "main", ImageVendor: "ublue-os", FedoraVersion: "42"},
+		Deployment: &Deployment{
+			Booted: DeploymentImage{Ref: "ostree-image-signed:docker://ghcr.io/ublue-os/bluefin:stable", Version: "42.20250101"},
+			Staged: &DeploymentImage{Ref: "ostree-image-signed:docker://ghcr.io/ublue-os/bluefin:stable", Version: "42.20250108"},
+		},
+		Hostname: "bluefin",
+		User:     "user",
+		Uptime:   "2 days 3 hours",
+		Announcements: []Announcement{{
+			ID:        "1a2b3c4d",
+			Feed:      "https://intranet.example.com/motd.json",
+			Title:     "VPN maintenance tonight",
+			Body:      "The VPN is down from 22:00 to 23:00 UTC.",
+			URL:       "https://status.example.com",
+			Published: time.Date(2025, time.January, 2, 8, 0, 0, 0, time.Local),
+		}},
+		Tip:     builtinTips[0].Text,
+		System:  []Widget{{Name: "memory", Label: "Memory", Value: "5.2 GiB / 15.5 GiB (33%)"}},
+		Tools:   []ToolStatus{{Name: "Eza", Enabled: true, Installed: true}, {Name: "Atuin", Enabled: false, Installed: false}},
+		Updates: &Updates{Brew: 3, Cask: 1, Flatpak: 2, Checked: time.Date(2025, time.January, 2, 7, 30, 0, 0, time.Local), Age: "2 hours ago", Hint: "brew upgrade && flatpak update"},
+		Date:    time.Date(2025, time.January, 2, 9, 30, 0, 0, time.Local),
+	}
+}
+
+// ReadData reads template data from a JSON file, e.g. one written by CollectData
+func ReadData(path string) (TemplateData, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return TemplateData{}, err
+	}
+	var data TemplateData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return TemplateData{}, fmt.Errorf("failed to parse template data: %w", err)
+	}
+	return data, nil
+}
+
+// CollectData gathers the template data for this machine. It only peeks at the next tip, so
+// reading the data, e.g. as JSON, doesn't advance the tip rotation.
+func CollectData(config Config) TemplateData {
+	return collectData(config, false)
+}
+
+// collectData gathers the template data, recording the tip as shown when advanceTip is set
+func collectData(config Config, advanceTip bool) TemplateData {
+	data := TemplateData{
+		Image:      getImageInfo(config.ImageInfoFile),
+		Deployment: CachedDeployment(),
+		Tip:        tipText(config, advanceTip),
+		Date:       time.Now(),
+	}
+
+	data.Hostname, _ = os.Hostname()
+	if u, err := user.Current(); err == nil {
+		data.User = u.Username
+	}
+	if up, ok := uptime(); ok {
+		data.Uptime = formatUptime(up)
+	}
+
+	shellName := filepath.Base(os.Getenv("SHELL"))
+	cfg, err := shell.LoadConfig(shellName)
+	if err != nil {
+		cfg = shell.DefaultConfig(shellName)
+	}
+	for _, tool := range shell.Tools {
+		_, lookErr := lookPath(tool.Binary)
+		data.Tools = append(data.Tools, ToolStatus{Name: tool.Name, Enabled: cfg.IsEnabled(tool.Name), Installed: lookErr == nil})
+	}
+
+	data.System = CollectWidgets(config.Widgets)
+	data.Announcements = unreadAnnouncements(config)
+
+	if config.CheckOutdated {
+		data.Updates = CachedUpdates()
+	}
+	return data
+}
+
+// uptime reads the time since boot from /proc/uptime on Linux or kern.boottime on macOS
+func uptime() (time.Duration, bool) {
+	switch runtime.GOOS {
+	case "linux":
+		raw, err := os.ReadFile(filepath.Join(procRoot, "uptime"))
+		if err != nil {
+			return 0, false
+		}
+		fields := strings.Fields(string(raw))
+		if len(fields) == 0 {
+			return 0, false
+		}
+		secs, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	case "darwin":
+		// "{ sec = 1700000000, usec = 0 } Tue Nov 14 22:13:20 2023"
+		out, err := exec.Command("sysctl", "-n", "kern.boottime").Output()
+		if err != nil {
+			return 0, false
+		}
+		var sec int64
+		if _, err := fmt.Sscanf(string(out), "{ sec = %d,", &sec); err != nil {
+			return 0, false
+		}
+		return time.Since(time.Unix(sec, 0)), true
+	}
+	return 0, false
+}
+
+// formatUptime renders a duration as its two largest units, e.g. "2 days 3 hours"
+func formatUptime(d time.Duration) string {
+	units := []struct {
+		name string
+		size time.Duration
+	}{{"day", 24 * time.Hour}, {"hour", time.Hour}, {"minute", time.Minute}}
+
+	var parts []string
+	for _, u := range units {
+		n := int(d / u.size)
+		d %= u.size
+		if n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, plural(u.name, n)))
+		} else if len(parts) > 0 {
+			break
+		}
+		if len(parts) == 2 {
+			break
+		}
+	}
+	if len(parts) == 0 {
+		return "less than a minute"
+	}
+	return strings.Join(parts, " ")
+}
+
+func plural(word string, n int) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
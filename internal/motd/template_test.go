@@ -0,0 +1,101 @@
+package motd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShippedTemplatesValidate(t *testing.T) {
+	names := ShippedTemplates()
+	if len(names) == 0 || names[0] == "" {
+		t.Fatalf("Expected shipped templates, got %v", names)
+	}
+	for _, name := range names {
+		text, err := LoadTemplate(name)
+		if err != nil {
+			t.Fatalf("LoadTemplate(%s) failed: %v", name, err)
+		}
+		if err := ValidateTemplate(text); err != nil {
+			t.Errorf("Shipped template %s is invalid: %v", name, err)
+		}
+	}
+}
+
+func TestLoadTemplateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mine.md")
+	if err := os.WriteFile(path, []byte("# Hi {{.User}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := LoadTemplate(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := ExecuteTemplate(text, SampleData())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "# Hi user" {
+		t.Errorf("Unexpected output %q", out)
+	}
+
+	if _, err := LoadTemplate("no-such-template"); err == nil {
+		t.Error("Expected an error for an unknown template")
+	}
+}
+
+func TestValidateTemplateRejectsUnknownFields(t *testing.T) {
+	if err := ValidateTemplate("{{.Kernel}}"); err == nil {
+		t.Error("Expected an unknown field to be rejected")
+	}
+	if err := ValidateTemplate("{{if .User}}"); err == nil {
+		t.Error("Expected a parse error")
+	}
+}
+
+func TestReadData(t *testing.T) {
+	raw, err := json.Marshal(SampleData())
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "data.json")
+	if err := os.WriteFile(path, raw, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ReadData(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.Hostname != "bluefin" || data.Updates == nil || data.Updates.Total() != 6 {
+		t.Errorf("Unexpected data %+v", data)
+	}
+
+	out, err := ExecuteTemplate("{{.Image.ImageName}} {{.Date.Format \"Jan 2\"}}", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out, "Bluefin Jan 2") {
+		t.Errorf("Unexpected output %q", out)
+	}
+}
+
+func TestFormatUptime(t *testing.T) {
+	tests := map[time.Duration]string{
+		30 * time.Second:               "less than a minute",
+		time.Minute:                    "1 minute",
+		3*time.Hour + 5*time.Minute:    "3 hours 5 minutes",
+		49*time.Hour + 30*time.Minute:  "2 days 1 hour",
+		24*time.Hour + 12*time.Minute:  "1 day",
+		10*24*time.Hour + 23*time.Hour: "10 days 23 hours",
+	}
+	for d, want := range tests {
+		if got := formatUptime(d); got != want {
+			t.Errorf("formatUptime(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,351 @@
+package motd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/hanthor/bluefin-cli/internal/env"
+)
+
+// Tip is one MOTD tip. Tip files are markdown with optional front-matter giving the
+// category and the conditions under which the tip is shown:
+//
+//	---
+//	category: history
+//	requires: atuin
+//	shell: bash, zsh
+//	os: linux
+//	---
+//	Search your shell history with `atuin` using Ctrl+R
+type Tip struct {
+	// ID names the tip in the rotation state: the file name without .md for tip files
+	ID       string `json:"id"`
+	Category string `json:"category,omitempty"`
+	// Requires lists binaries that must be on PATH
+	Requires []string `json:"requires,omitempty"`
+	// Shell lists the shells the tip applies to, all when empty
+	Shell []string `json:"shell,omitempty"`
+	// OS lists the operating systems the tip applies to (linux, darwin), all when empty
+	OS   []string `json:"os,omitempty"`
+	Text string   `json:"text"`
+	// Path is the tip file, empty for built-in tips
+	Path string `json:"path,omitempty"`
+}
+
+var builtinTips = []Tip{
+	{ID: "brew-install", Category: "packages", Requires: []string{"brew"}, Text: "Use `brew search` and `brew install` to install packages. Homebrew will take care of updates automatically"},
+	{ID: "tldr", Category: "commands", Requires: []string{"tldr"}, Text: "`tldr vim` will give you the basic rundown on commands for a given tool"},
+	{ID: "profiling", Category: "system", Text: "Performance profiling tools are built-in: try `top`, `htop`, and other debugging tools"},
+	{ID: "switch-shells", Category: "shell", Text: "Switch shells safely: change your shell in Terminal settings instead of system-wide"},
+	{ID: "devcontainers-anywhere", Category: "containers", Text: "Container development is OS-agnostic - your devcontainers work on Linux, macOS, and Windows"},
+	{ID: "docker-compose", Category: "containers", Requires: []string{"docker"}, Text: "Use `docker compose` for multi-container development if devcontainers don't fit your workflow"},
+	{ID: "cloud-native", Category: "bluefin", OS: []string{"linux"}, Text: "Bluefin separates the OS from your development environment - embrace the cloud-native workflow"},
+	{ID: "devpod", Category: "containers", Text: "Check out DevPod for open-source, client-only development environments that work with any IDE"},
+	{ID: "devcontainer-json", Category: "containers", Text: "Develop with devcontainers! Use `devcontainer.json` files in your projects for isolated, reproducible environments"},
+	{ID: "vscode-devcontainers", Category: "containers", Requires: []string{"code"}, Text: "VS Code comes with devcontainers extension pre-installed - perfect for containerized development"},
+	{ID: "eza", Category: "tools", Requires: []string{"eza"}, Text: "Use `eza -l --icons` for a beautiful file listing with icons and colors"},
+	{ID: "bat", Category: "tools", Requires: []string{"bat"}, Text: "The `bat` command is like `cat` but with syntax highlighting and Git integration"},
+	{ID: "zoxide", Category: "tools", Requires: []string{"zoxide"}, Text: "Navigate directories faster with `zoxide` - just use `z <partial-name>` to jump around"},
+	{ID: "atuin", Category: "tools", Requires: []string{"atuin"}, Text: "Search your shell history with `atuin` using Ctrl+R for a better history search experience"},
+	{ID: "starship", Category: "prompt", Requires: []string{"starship"}, Text: "Customize your prompt with `starship config` to modify colors, icons, and modules"},
+}
+
+var validTipID = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// ParseTip reads a tip from markdown with optional front-matter
+func ParseTip(id, content string) (Tip, error) {
+	tip := Tip{ID: id}
+	content = strings.TrimSpace(content)
+
+	if rest, ok := strings.CutPrefix(content, "---\n"); ok {
+		header, body, found := strings.Cut(rest, "\n---")
+		if !found {
+			return Tip{}, fmt.Errorf("tip %s: front-matter is not closed with ---", id)
+		}
+		for _, line := range strings.Split(header, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			key, value, ok := strings.Cut(line, ":")
+			if !ok {
+				return Tip{}, fmt.Errorf("tip %s: invalid front-matter line %q", id, line)
+			}
+			value = strings.TrimSpace(value)
+			switch strings.TrimSpace(key) {
+			case "category":
+				tip.Category = value
+			case "requires":
+				tip.Requires = splitList(value)
+			case "shell":
+				tip.Shell = splitList(value)
+			case "os":
+				tip.OS = splitList(value)
+			default:
+				return Tip{}, fmt.Errorf("tip %s: unknown front-matter key %q", id, key)
+			}
+		}
+		content = strings.TrimSpace(body)
+	}
+
+	if content == "" {
+		return Tip{}, fmt.Errorf("tip %s is empty", id)
+	}
+	tip.Text = content
+	return tip, nil
+}
+
+// Markdown formats a tip as a tip file
+func (t Tip) Markdown() string {
+	var header []string
+	if t.Category != "" {
+		header = append(header, "category: "+t.Category)
+	}
+	if len(t.Requires) > 0 {
+		header = append(header, "requires: "+strings.Join(t.Requires, ", "))
+	}
+	if len(t.Shell) > 0 {
+		header = append(header, "shell: "+strings.Join(t.Shell, ", "))
+	}
+	if len(t.OS) > 0 {
+		header = append(header, "os: "+strings.Join(t.OS, ", "))
+	}
+	if len(header) == 0 {
+		return t.Text + "\n"
+	}
+	return "---\n" + strings.Join(header, "\n") + "\n---\n" + t.Text + "\n"
+}
+
+// Applies reports whether the tip's conditions hold for the current shell and machine
+func (t Tip) Applies() bool {
+	for _, bin := range t.Requires {
+		if _, err := lookPath(bin); err != nil {
+			return false
+		}
+	}
+	if len(t.Shell) > 0 && !slices.Contains(t.Shell, filepath.Base(os.Getenv("SHELL"))) {
+		return false
+	}
+	if len(t.OS) > 0 && !slices.Contains(t.OS, runtime.GOOS) && !(runtime.GOOS == "darwin" && slices.Contains(t.OS, "macos")) {
+		return false
+	}
+	return true
+}
+
+// UserTipsDir is where 'motd tips add' writes tips
+func UserTipsDir() (string, error) {
+	dir, err := env.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "tips"), nil
+}
+
+// LoadTips returns the tips of the tips directory, or the built-in tips when it isn't set,
+// followed by the tips added with 'motd tips add'. Broken tip files are skipped and reported.
+func LoadTips(config Config) ([]Tip, []error) {
+	var tips []Tip
+	var errs []error
+
+	dirs := []string{config.TipsDirectory}
+	if config.TipsDirectory == "" {
+		tips = append(tips, builtinTips...)
+		dirs = nil
+	}
+	if dir, err := UserTipsDir(); err == nil && dir != config.TipsDirectory {
+		dirs = append(dirs, dir)
+	}
+
+	for _, dir := range dirs {
+		files, _ := filepath.Glob(filepath.Join(dir, "*.md"))
+		for _, path := range files {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			tip, err := ParseTip(strings.TrimSuffix(filepath.Base(path), ".md"), string(content))
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			tip.Path = path
+			tips = append(tips, tip)
+		}
+	}
+	return tips, errs
+}
+
+// AddTip writes a tip to the user tips directory, failing if its ID is taken
+func AddTip(tip Tip) (string, error) {
+	if !validTipID.MatchString(tip.ID) {
+		return "", fmt.Errorf("invalid tip ID %q: use letters, digits, '.', '_' and '-'", tip.ID)
+	}
+	if _, err := ParseTip(tip.ID, tip.Markdown()); err != nil {
+		return "", err
+	}
+
+	dir, err := UserTipsDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	path := filepath.Join(dir, tip.ID+".md")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return "", fmt.Errorf("a tip named %s already exists", tip.ID)
+		}
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(tip.Markdown()); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// tipState is the rotation: the IDs shown since every applicable tip was last shown, and the
+// tip picked for the next NextTip so that peeking at it shows the same one
+type tipState struct {
+	Shown []string `json:"shown"`
+	Next  string   `json:"next,omitempty"`
+}
+
+func tipStatePath() (string, error) {
+	dir, err := env.GetStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "tips.json"), nil
+}
+
+func readTipState() tipState {
+	var state tipState
+	if path, err := tipStatePath(); err == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			json.Unmarshal(data, &state)
+		}
+	}
+	return state
+}
+
+func writeTipState(state tipState) error {
+	if _, err := env.EnsureStateDir(); err != nil {
+		return err
+	}
+	path, err := tipStatePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return env.WriteFileAtomic(path, data, 0644)
+}
+
+// ShownTips returns the IDs shown in the current rotation
+func ShownTips() map[string]bool {
+	shown := make(map[string]bool)
+	for _, id := range readTipState().Shown {
+		shown[id] = true
+	}
+	return shown
+}
+
+// NextTip picks a random applicable tip that hasn't been shown in this rotation and records
+// it. Once every applicable tip has been shown, a new rotation starts. It returns false
+// when no tip applies.
+func NextTip(config Config) (Tip, bool) {
+	tip, state, ok := pickTip(config)
+	if !ok {
+		return Tip{}, false
+	}
+	state.Shown, state.Next = append(state.Shown, tip.ID), ""
+	// The tip is shown even if the rotation can't be saved
+	writeTipState(state)
+	return tip, true
+}
+
+// PeekTip returns the tip NextTip will show without recording it, so the rotation doesn't
+// advance. The pick is saved, so later peeks and the next NextTip agree with it.
+func PeekTip(config Config) (Tip, bool) {
+	tip, state, ok := pickTip(config)
+	if ok && state.Next != tip.ID {
+		state.Next = tip.ID
+		writeTipState(state)
+	}
+	return tip, ok
+}
+
+// pickTip returns the tip saved by PeekTip if it is still unseen and applicable, or else a
+// random unseen applicable one, and the rotation state it was picked from, reset when every
+// tip had been shown
+func pickTip(config Config) (Tip, tipState, bool) {
+	tips, _ := LoadTips(config)
+	var applicable []Tip
+	for _, tip := range tips {
+		if tip.Applies() {
+			applicable = append(applicable, tip)
+		}
+	}
+	if len(applicable) == 0 {
+		return Tip{}, tipState{}, false
+	}
+
+	state := readTipState()
+	shown := make(map[string]bool)
+	for _, id := range state.Shown {
+		shown[id] = true
+	}
+	var unseen []Tip
+	for _, tip := range applicable {
+		if !shown[tip.ID] {
+			unseen = append(unseen, tip)
+		}
+	}
+	if len(unseen) == 0 {
+		state.Shown, unseen = nil, applicable
+	}
+
+	for _, tip := range unseen {
+		if tip.ID == state.Next {
+			return tip, state, true
+		}
+	}
+	return unseen[rand.IntN(len(unseen))], state, true
+}
+
+// tipText returns the text of the next tip, advancing the rotation when advance is set, or
+// "" when none applies
+func tipText(config Config, advance bool) string {
+	pick := PeekTip
+	if advance {
+		pick = NextTip
+	}
+	tip, ok := pick(config)
+	if !ok {
+		return ""
+	}
+	return tip.Text
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,184 @@
+package motd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestParseTip(t *testing.T) {
+	tip, err := ParseTip("atuin-search", `---
+category: history
+requires: atuin
+shell: bash, zsh
+os: linux, macos
+---
+Search your shell history with `+"`atuin`"+`
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Tip{
+		ID:       "atuin-search",
+		Category: "history",
+		Requires: []string{"atuin"},
+		Shell:    []string{"bash", "zsh"},
+		OS:       []string{"linux", "macos"},
+		Text:     "Search your shell history with `atuin`",
+	}
+	if !reflect.DeepEqual(tip, want) {
+		t.Errorf("ParseTip() = %+v, want %+v", tip, want)
+	}
+
+	parsed, err := ParseTip(tip.ID, tip.Markdown())
+	if err != nil || !reflect.DeepEqual(parsed, want) {
+		t.Errorf("Markdown() did not round-trip: %+v (%v)", parsed, err)
+	}
+
+	if tip, err := ParseTip("plain", "Just text\n"); err != nil || tip.Text != "Just text" || tip.Category != "" {
+		t.Errorf("Unexpected plain tip %+v (%v)", tip, err)
+	}
+	for _, bad := range []string{"", "---\ncategory: x\n", "---\ncolor: red\n---\ntext", "---\ncategory: x\n---\n"} {
+		if _, err := ParseTip("bad", bad); err == nil {
+			t.Errorf("Expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestTipApplies(t *testing.T) {
+	origLookPath := lookPath
+	defer func() { lookPath = origLookPath }()
+	lookPath = func(file string) (string, error) {
+		if file == "atuin" {
+			return "/usr/bin/atuin", nil
+		}
+		return "", errors.New("not found")
+	}
+	t.Setenv("SHELL", "/bin/zsh")
+
+	tests := []struct {
+		tip  Tip
+		want bool
+	}{
+		{Tip{}, true},
+		{Tip{Requires: []string{"atuin"}}, true},
+		{Tip{Requires: []string{"atuin", "zoxide"}}, false},
+		{Tip{Shell: []string{"bash", "zsh"}}, true},
+		{Tip{Shell: []string{"fish"}}, false},
+		{Tip{OS: []string{runtime.GOOS}}, true},
+		{Tip{OS: []string{"plan9"}}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.tip.Applies(); got != tt.want {
+			t.Errorf("%+v.Applies() = %v, want %v", tt.tip, got, tt.want)
+		}
+	}
+}
+
+func TestNextTipRotation(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("HOMEBREW_PREFIX", "")
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"one.md":    "First tip",
+		"two.md":    "---\ncategory: misc\n---\nSecond tip",
+		"three.md":  "Third tip",
+		"never.md":  "---\nrequires: no-such-binary-bluefin\n---\nNever shown",
+		"broken.md": "---\nunknown: key\n---\nBroken",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config := DefaultConfig()
+	config.TipsDirectory = dir
+
+	tips, errs := LoadTips(config)
+	if len(tips) != 4 || len(errs) != 1 {
+		t.Fatalf("Expected 4 tips and 1 error, got %d and %v", len(tips), errs)
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		tip, ok := NextTip(config)
+		if !ok {
+			t.Fatal("Expected a tip")
+		}
+		if seen[tip.ID] {
+			t.Fatalf("Tip %s repeated before the rotation finished", tip.ID)
+		}
+		seen[tip.ID] = true
+	}
+	if seen["never"] {
+		t.Error("Expected the tip with an unmet condition to be skipped")
+	}
+
+	// The fourth tip starts a new rotation
+	if _, ok := NextTip(config); !ok {
+		t.Fatal("Expected a tip")
+	}
+	if shown := ShownTips(); len(shown) != 1 {
+		t.Errorf("Expected a new rotation, got %v", shown)
+	}
+}
+
+func TestPeekTip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("HOMEBREW_PREFIX", "")
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	dir := t.TempDir()
+	for _, name := range []string{"one", "two", "three", "four"} {
+		if err := os.WriteFile(filepath.Join(dir, name+".md"), []byte(name+" tip"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config := DefaultConfig()
+	config.TipsDirectory = dir
+
+	// Peeks show the tip the next NextTip records, through a full rotation and into the next
+	for i := 0; i < 6; i++ {
+		first, ok := PeekTip(config)
+		if !ok {
+			t.Fatal("Expected a tip")
+		}
+		if again, _ := PeekTip(config); again.ID != first.ID {
+			t.Fatalf("Expected peeks to agree, got %s and %s", first.ID, again.ID)
+		}
+		if next, _ := NextTip(config); next.ID != first.ID {
+			t.Fatalf("Expected NextTip to show the peeked %s, got %s", first.ID, next.ID)
+		}
+	}
+}
+
+func TestAddTip(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("HOMEBREW_PREFIX", "")
+
+	tip := Tip{ID: "k9s", Category: "kubernetes", Requires: []string{"k9s"}, Text: "Run `k9s` for a cluster dashboard"}
+	path, err := AddTip(tip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != filepath.Join(home, ".config", "bluefin-cli", "tips", "k9s.md") {
+		t.Errorf("Unexpected path %s", path)
+	}
+	if _, err := AddTip(tip); err == nil {
+		t.Error("Expected a duplicate ID to be rejected")
+	}
+	if _, err := AddTip(Tip{ID: "../escape", Text: "x"}); err == nil {
+		t.Error("Expected an invalid ID to be rejected")
+	}
+
+	tips, _ := LoadTips(DefaultConfig())
+	if len(tips) != len(builtinTips)+1 || tips[len(tips)-1].ID != "k9s" {
+		t.Errorf("Expected the built-in tips and the added tip, got %d tips", len(tips))
+	}
+}
@@ -0,0 +1,171 @@
+package motd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/hanthor/bluefin-cli/internal/env"
+)
+
+// updatesMaxAge is how old the cached update count may get before the MOTD refreshes it
+const updatesMaxAge = 6 * time.Hour
+
+// refreshTimeout stops a new background refresh from starting while one is still running
+const refreshTimeout = 10 * time.Minute
+
+// Updates counts outdated Homebrew formulae and casks and flatpak applications
+type Updates struct {
+	Brew    int `json:"brew"`
+	Cask    int `json:"cask"`
+	Flatpak int `json:"flatpak"`
+	// Checked is when the counts were last refreshed
+	Checked time.Time `json:"checked"`
+	// Age is how long ago the counts were refreshed, e.g. "2 hours ago"
+	Age string `json:"age"`
+	// Hint is the command that upgrades the outdated packages, e.g. "brew upgrade"
+	Hint string `json:"hint"`
+}
+
+// Total is the number of outdated packages
+func (u Updates) Total() int {
+	return u.Brew + u.Cask + u.Flatpak
+}
+
+// execCommand builds the commands that count outdated packages, replaced in tests
+var execCommand = exec.Command
+
+// startBackground runs bluefin-cli with args detached from the shell, replaced in tests
+var startBackground = func(args ...string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(exe, args...)
+	// A new session keeps the refresh alive when the terminal closes
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
+
+// refreshInBackground starts bluefin-cli with args unless a refresh holding lock is still
+// running. The refresh removes the lock when it finishes; a stale one is from a refresh
+// that died.
+func refreshInBackground(lock string, args ...string) {
+	if info, err := os.Stat(lock); err == nil && time.Since(info.ModTime()) < refreshTimeout {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(lock), 0755); err != nil {
+		return
+	}
+	if err := os.WriteFile(lock, nil, 0644); err != nil {
+		return
+	}
+	if err := startBackground(args...); err != nil {
+		os.Remove(lock)
+	}
+}
+
+func updatesPath() (string, error) {
+	dir, err := env.GetStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "outdated.json"), nil
+}
+
+// CachedUpdates returns the update counts from the last refresh, or nil if there was none
+func CachedUpdates() *Updates {
+	path, err := updatesPath()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var updates Updates
+	if err := json.Unmarshal(data, &updates); err != nil || updates.Checked.IsZero() {
+		return nil
+	}
+	updates.Age = humanize.Time(updates.Checked)
+	updates.Hint = upgradeHint(updates)
+	return &updates
+}
+
+// RefreshUpdates counts outdated packages now and caches the result in the state directory
+func RefreshUpdates() (*Updates, error) {
+	updates := countUpdates()
+	updates.Checked = time.Now()
+
+	if _, err := env.EnsureStateDir(); err != nil {
+		return nil, err
+	}
+	path, err := updatesPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(updates, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := env.WriteFileAtomic(path, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to cache update count: %w", err)
+	}
+	os.Remove(path + ".lock")
+
+	updates.Age = humanize.Time(updates.Checked)
+	updates.Hint = upgradeHint(*updates)
+	return updates, nil
+}
+
+// refreshUpdatesIfStale starts a background refresh when the cached count is missing or
+// older than updatesMaxAge, so the MOTD never waits for brew or flatpak
+func refreshUpdatesIfStale(cached *Updates) {
+	if cached != nil && time.Since(cached.Checked) < updatesMaxAge {
+		return
+	}
+	if path, err := updatesPath(); err == nil {
+		refreshInBackground(path+".lock", "motd", "updates", "--refresh")
+	}
+}
+
+// countUpdates counts outdated formulae, casks and flatpaks
+func countUpdates() *Updates {
+	updates := &Updates{}
+
+	if out, err := execCommand("brew", "outdated", "--json=v2").Output(); err == nil {
+		var report struct {
+			Formulae []json.RawMessage `json:"formulae"`
+			Casks    []json.RawMessage `json:"casks"`
+		}
+		if json.Unmarshal(out, &report) == nil {
+			updates.Brew, updates.Cask = len(report.Formulae), len(report.Casks)
+		}
+	}
+
+	if out, err := execCommand("flatpak", "remote-ls", "--updates", "--app", "--columns=application").Output(); err == nil {
+		updates.Flatpak = len(strings.Fields(string(out)))
+	}
+	return updates
+}
+
+// upgradeHint returns the command that upgrades everything outdated
+func upgradeHint(u Updates) string {
+	var cmds []string
+	if u.Brew+u.Cask > 0 {
+		cmds = append(cmds, "brew upgrade")
+	}
+	if u.Flatpak > 0 {
+		cmds = append(cmds, "flatpak update")
+	}
+	return strings.Join(cmds, " && ")
+}
@@ -0,0 +1,110 @@
+package motd
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigCheckOutdatedLegacyString(t *testing.T) {
+	for input, want := range map[string]bool{
+		`{"check-outdated": "true"}`:  true,
+		`{"check-outdated": "false"}`: false,
+		`{"check-outdated": true}`:    true,
+		`{"default-theme": "dark"}`:   false,
+	} {
+		config := DefaultConfig()
+		if err := json.Unmarshal([]byte(input), &config); err != nil {
+			t.Fatalf("Unmarshal(%s) failed: %v", input, err)
+		}
+		if config.CheckOutdated != want {
+			t.Errorf("Unmarshal(%s): CheckOutdated = %v, want %v", input, config.CheckOutdated, want)
+		}
+	}
+
+	config := DefaultConfig()
+	if err := json.Unmarshal([]byte(`{"default-theme": "dark"}`), &config); err != nil || config.DefaultTheme != "dark" {
+		t.Errorf("Expected other fields to be kept, got %+v (%v)", config, err)
+	}
+	if err := json.Unmarshal([]byte(`{"check-outdated": "sometimes"}`), &config); err == nil {
+		t.Error("Expected an invalid value to be rejected")
+	}
+}
+
+func TestRefreshUpdates(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	origExecCommand := execCommand
+	defer func() { execCommand = origExecCommand }()
+
+	execCommand = func(name string, arg ...string) *exec.Cmd {
+		if name == "brew" {
+			return exec.Command("echo", `{"formulae": [{}, {}], "casks": [{}]}`)
+		}
+		return exec.Command("printf", "org.gimp.GIMP\n")
+	}
+
+	if CachedUpdates() != nil {
+		t.Fatal("Expected no cached updates before the first refresh")
+	}
+	if _, err := RefreshUpdates(); err != nil {
+		t.Fatal(err)
+	}
+
+	updates := CachedUpdates()
+	if updates == nil {
+		t.Fatal("Expected cached updates")
+	}
+	if updates.Brew != 2 || updates.Cask != 1 || updates.Flatpak != 1 || updates.Total() != 4 {
+		t.Errorf("Unexpected counts %+v", updates)
+	}
+	if updates.Hint != "brew upgrade && flatpak update" {
+		t.Errorf("Unexpected hint %q", updates.Hint)
+	}
+	if updates.Age == "" {
+		t.Error("Expected the cache age")
+	}
+}
+
+func TestRefreshUpdatesIfStale(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	origStartBackground := startBackground
+	defer func() { startBackground = origStartBackground }()
+
+	started := 0
+	startBackground = func(args ...string) error {
+		if strings.Join(args, " ") != "motd updates --refresh" {
+			t.Errorf("Unexpected command %v", args)
+		}
+		started++
+		return nil
+	}
+
+	refreshUpdatesIfStale(&Updates{Checked: time.Now()})
+	if started != 0 {
+		t.Error("Expected no refresh for a fresh cache")
+	}
+
+	refreshUpdatesIfStale(nil)
+	refreshUpdatesIfStale(&Updates{Checked: time.Now().Add(-24 * time.Hour)})
+	if started != 1 {
+		t.Errorf("Expected one refresh while the first is running, got %d", started)
+	}
+
+	// A refresh that died leaves a stale lock behind
+	path, _ := updatesPath()
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(path+".lock", old, old); err != nil {
+		t.Fatal(err)
+	}
+	refreshUpdatesIfStale(nil)
+	if started != 2 {
+		t.Errorf("Expected a new refresh after a stale lock, got %d", started)
+	}
+	if _, err := os.Stat(filepath.Dir(path)); err != nil {
+		t.Errorf("Expected the state directory to be created: %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package pkgmgr
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ostreeBootedPath exists on image-based systems (Silverblue, Bluefin, ...) where
+// dnf cannot layer packages onto the running deployment
+var ostreeBootedPath = "/run/ostree-booted"
+
+// DNF installs RPM packages on Fedora-family systems
+type DNF struct{}
+
+func (DNF) Name() string { return "dnf" }
+
+func (DNF) Detect() bool {
+	if _, err := lookPath("dnf"); err != nil {
+		return false
+	}
+	if _, err := os.Stat(ostreeBootedPath); err == nil {
+		return false
+	}
+	return true
+}
+
+func (d DNF) IsInstalled(pkg string) bool {
+	_, err := d.Version(pkg)
+	return err == nil
+}
+
+func (DNF) Install(pkgs ...string) error {
+	return runPrivileged("dnf", append([]string{"install", "-y"}, pkgs...)...)
+}
+
+func (DNF) Uninstall(pkgs ...string) error {
+	return runPrivileged("dnf", append([]string{"remove", "-y"}, pkgs...)...)
+}
+
+func (DNF) Version(pkg string) (string, error) {
+	out, err := output("rpm", "-q", "--queryformat", "%{VERSION}-%{RELEASE}", pkg)
+	if err != nil || out == "" {
+		return "", fmt.Errorf("%s is not installed via dnf", pkg)
+	}
+	return out, nil
+}
+
+// APT installs Debian packages on Debian and Ubuntu systems
+type APT struct{}
+
+func (APT) Name() string { return "apt" }
+
+func (APT) Detect() bool {
+	if _, err := lookPath("apt-get"); err != nil {
+		return false
+	}
+	_, err := lookPath("dpkg-query")
+	return err == nil
+}
+
+func (APT) IsInstalled(pkg string) bool {
+	out, err := output("dpkg-query", "-W", "-f=${Status}", pkg)
+	return err == nil && strings.Contains(out, "install ok installed")
+}
+
+func (APT) Install(pkgs ...string) error {
+	return runPrivileged("apt-get", append([]string{"install", "-y"}, pkgs...)...)
+}
+
+func (APT) Uninstall(pkgs ...string) error {
+	return runPrivileged("apt-get", append([]string{"remove", "-y"}, pkgs...)...)
+}
+
+func (a APT) Version(pkg string) (string, error) {
+	if !a.IsInstalled(pkg) {
+		return "", fmt.Errorf("%s is not installed via apt", pkg)
+	}
+	return output("dpkg-query", "-W", "-f=${Version}", pkg)
+}
+
+// Pacman installs packages on Arch-based systems
+type Pacman struct{}
+
+func (Pacman) Name() string { return "pacman" }
+
+func (Pacman) Detect() bool {
+	_, err := lookPath("pacman")
+	return err == nil
+}
+
+func (p Pacman) IsInstalled(pkg string) bool {
+	_, err := p.Version(pkg)
+	return err == nil
+}
+
+func (Pacman) Install(pkgs ...string) error {
+	return runPrivileged("pacman", append([]string{"-S", "--needed", "--noconfirm"}, pkgs...)...)
+}
+
+func (Pacman) Uninstall(pkgs ...string) error {
+	return runPrivileged("pacman", append([]string{"-R", "--noconfirm"}, pkgs...)...)
+}
+
+// Version parses `pacman -Q <pkg>`, which prints "<pkg> <version>"
+func (Pacman) Version(pkg string) (string, error) {
+	out, err := output("pacman", "-Q", pkg)
+	fields := strings.Fields(out)
+	if err != nil || len(fields) < 2 {
+		return "", fmt.Errorf("%s is not installed via pacman", pkg)
+	}
+	return fields[1], nil
+}
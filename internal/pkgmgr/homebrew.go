@@ -0,0 +1,42 @@
+package pkgmgr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Homebrew installs formulae and casks with brew
+type Homebrew struct{}
+
+func (Homebrew) Name() string { return "brew" }
+
+func (Homebrew) Detect() bool {
+	_, err := lookPath("brew")
+	return err == nil
+}
+
+func (h Homebrew) IsInstalled(pkg string) bool {
+	_, err := h.Version(pkg)
+	return err == nil
+}
+
+func (Homebrew) Install(pkgs ...string) error {
+	return run("brew", append([]string{"install"}, pkgs...)...)
+}
+
+func (Homebrew) Uninstall(pkgs ...string) error {
+	return run("brew", append([]string{"uninstall"}, pkgs...)...)
+}
+
+// Version parses `brew list --versions <pkg>`, which prints "<pkg> <version> [<version>...]"
+func (Homebrew) Version(pkg string) (string, error) {
+	out, err := output("brew", "list", "--versions", pkg)
+	if err != nil {
+		return "", fmt.Errorf("%s is not installed via brew", pkg)
+	}
+	fields := strings.Fields(out)
+	if len(fields) < 2 {
+		return "", fmt.Errorf("%s is not installed via brew", pkg)
+	}
+	return fields[len(fields)-1], nil
+}
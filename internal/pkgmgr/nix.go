@@ -0,0 +1,102 @@
+package pkgmgr
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// NixProfile installs nixpkgs packages into the user's default nix profile
+type NixProfile struct{}
+
+type nixElement struct {
+	Name       string   `json:"-"`
+	AttrPath   string   `json:"attrPath"`
+	StorePaths []string `json:"storePaths"`
+}
+
+func (NixProfile) Name() string { return "nix" }
+
+func (NixProfile) Detect() bool {
+	_, err := lookPath("nix")
+	return err == nil
+}
+
+func (n NixProfile) IsInstalled(pkg string) bool {
+	_, ok := n.find(pkg)
+	return ok
+}
+
+func (NixProfile) Install(pkgs ...string) error {
+	args := []string{"profile", "install"}
+	for _, pkg := range pkgs {
+		args = append(args, "nixpkgs#"+pkg)
+	}
+	return run("nix", args...)
+}
+
+func (NixProfile) Uninstall(pkgs ...string) error {
+	return run("nix", append([]string{"profile", "remove"}, pkgs...)...)
+}
+
+// Version derives the version from the element's store path (/nix/store/<hash>-<pkg>-<version>)
+func (n NixProfile) Version(pkg string) (string, error) {
+	el, ok := n.find(pkg)
+	if !ok {
+		return "", fmt.Errorf("%s is not installed via nix", pkg)
+	}
+	for _, p := range el.StorePaths {
+		base := filepath.Base(p)
+		if i := strings.Index(base, "-"); i >= 0 {
+			base = base[i+1:]
+		}
+		if v, found := strings.CutPrefix(base, pkg+"-"); found {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("could not determine version of %s", pkg)
+}
+
+func (NixProfile) find(pkg string) (nixElement, bool) {
+	out, err := output("nix", "profile", "list", "--json")
+	if err != nil {
+		return nixElement{}, false
+	}
+	elements, err := parseNixProfile([]byte(out))
+	if err != nil {
+		return nixElement{}, false
+	}
+	for _, el := range elements {
+		if el.Name == pkg || strings.HasSuffix(el.AttrPath, "."+pkg) {
+			return el, true
+		}
+	}
+	return nixElement{}, false
+}
+
+// parseNixProfile handles both the newer keyed "elements" object and the older list form
+func parseNixProfile(data []byte) ([]nixElement, error) {
+	var profile struct {
+		Elements json.RawMessage `json:"elements"`
+	}
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, err
+	}
+
+	var keyed map[string]nixElement
+	if err := json.Unmarshal(profile.Elements, &keyed); err == nil {
+		elements := make([]nixElement, 0, len(keyed))
+		for name, el := range keyed {
+			el.Name = name
+			elements = append(elements, el)
+		}
+		return elements, nil
+	}
+
+	var listed []nixElement
+	if err := json.Unmarshal(profile.Elements, &listed); err != nil {
+		return nil, fmt.Errorf("unrecognized nix profile format: %w", err)
+	}
+	return listed, nil
+}
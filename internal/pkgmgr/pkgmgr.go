@@ -0,0 +1,113 @@
+package pkgmgr
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// PackageManager is a package backend that bluefin-cli can install software with
+type PackageManager interface {
+	// Name is the short identifier used in config and the install ledger (e.g. "brew")
+	Name() string
+	// Detect reports whether the backend is usable on this machine
+	Detect() bool
+	// IsInstalled reports whether the package is installed through this backend
+	IsInstalled(pkg string) bool
+	// Install installs one or more packages
+	Install(pkgs ...string) error
+	// Uninstall removes one or more packages
+	Uninstall(pkgs ...string) error
+	// Version returns the installed version of a package
+	Version(pkg string) (string, error)
+}
+
+var (
+	// For testing
+	execCommand = exec.Command
+	lookPath    = exec.LookPath
+	geteuid     = os.Geteuid
+)
+
+var (
+	registry = make(map[string]PackageManager)
+	names    []string
+)
+
+func init() {
+	Register(Homebrew{})
+	Register(DNF{})
+	Register(APT{})
+	Register(Pacman{})
+	Register(NixProfile{})
+}
+
+// Register makes a backend available under its Name
+func Register(pm PackageManager) {
+	if _, exists := registry[pm.Name()]; !exists {
+		names = append(names, pm.Name())
+	}
+	registry[pm.Name()] = pm
+}
+
+// Lookup returns the backend registered under name
+func Lookup(name string) (PackageManager, bool) {
+	pm, ok := registry[name]
+	return pm, ok
+}
+
+// Names returns the registered backend names in registration order
+func Names() []string {
+	return append([]string(nil), names...)
+}
+
+// Detected returns the backends from the preference order that are usable on this machine
+func Detected(preference []string) []PackageManager {
+	var detected []PackageManager
+	seen := make(map[string]bool)
+
+	for _, name := range preference {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		pm, ok := Lookup(name)
+		if !ok || !pm.Detect() {
+			continue
+		}
+		detected = append(detected, pm)
+	}
+
+	return detected
+}
+
+// run executes a command attached to the terminal so progress and prompts are visible
+func run(name string, args ...string) error {
+	cmd := execCommand(name, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s %s failed: %w", name, strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+// runPrivileged is like run but goes through sudo when not already root
+func runPrivileged(name string, args ...string) error {
+	if geteuid() == 0 {
+		return run(name, args...)
+	}
+	if _, err := lookPath("sudo"); err != nil {
+		return fmt.Errorf("%s requires root privileges and sudo was not found", name)
+	}
+	return run("sudo", append([]string{name}, args...)...)
+}
+
+// output runs a command quietly and returns its trimmed stdout
+func output(name string, args ...string) (string, error) {
+	out, err := execCommand(name, args...).Output()
+	return strings.TrimSpace(string(out)), err
+}
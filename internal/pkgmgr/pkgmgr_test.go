@@ -0,0 +1,150 @@
+package pkgmgr
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeBinary writes an executable shell script named name into dir
+func fakeBinary(t *testing.T, dir, name, script string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatalf("Failed to write fake %s: %v", name, err)
+	}
+}
+
+func TestDetectedFollowsPreference(t *testing.T) {
+	binDir := t.TempDir()
+	fakeBinary(t, binDir, "brew", "exit 0")
+	fakeBinary(t, binDir, "pacman", "exit 0")
+	t.Setenv("PATH", binDir)
+
+	detected := Detected([]string{"pacman", "dnf", "unknown", "brew", "pacman"})
+
+	var got []string
+	for _, pm := range detected {
+		got = append(got, pm.Name())
+	}
+	if strings.Join(got, ",") != "pacman,brew" {
+		t.Errorf("Expected [pacman brew], got %v", got)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for _, name := range []string{"brew", "dnf", "apt", "pacman", "nix"} {
+		pm, ok := Lookup(name)
+		if !ok {
+			t.Errorf("Expected backend %s to be registered", name)
+			continue
+		}
+		if pm.Name() != name {
+			t.Errorf("Expected backend name %s, got %s", name, pm.Name())
+		}
+	}
+
+	if _, ok := Lookup("zypper"); ok {
+		t.Error("Did not expect zypper backend to be registered")
+	}
+}
+
+func TestHomebrewVersion(t *testing.T) {
+	binDir := t.TempDir()
+	fakeBinary(t, binDir, "brew", `if [ "$3" = "eza" ]; then echo "eza 0.18.0 0.18.1"; exit 0; fi; exit 1`)
+	t.Setenv("PATH", binDir)
+
+	brew := Homebrew{}
+	version, err := brew.Version("eza")
+	if err != nil {
+		t.Fatalf("Version() failed: %v", err)
+	}
+	if version != "0.18.1" {
+		t.Errorf("Expected version 0.18.1, got %s", version)
+	}
+
+	if brew.IsInstalled("bat") {
+		t.Error("Expected bat to be reported as not installed")
+	}
+}
+
+func TestPacmanVersion(t *testing.T) {
+	binDir := t.TempDir()
+	fakeBinary(t, binDir, "pacman", `echo "zoxide 0.9.4-1"`)
+	t.Setenv("PATH", binDir)
+
+	version, err := Pacman{}.Version("zoxide")
+	if err != nil {
+		t.Fatalf("Version() failed: %v", err)
+	}
+	if version != "0.9.4-1" {
+		t.Errorf("Expected version 0.9.4-1, got %s", version)
+	}
+}
+
+func TestParseNixProfile(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{
+			"Keyed elements",
+			`{"elements":{"eza":{"attrPath":"legacyPackages.x86_64-linux.eza","storePaths":["/nix/store/abc123-eza-0.18.0"]}},"version":3}`,
+		},
+		{
+			"Listed elements",
+			`{"elements":[{"attrPath":"legacyPackages.x86_64-linux.eza","storePaths":["/nix/store/abc123-eza-0.18.0"]}],"version":2}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			elements, err := parseNixProfile([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("parseNixProfile() failed: %v", err)
+			}
+			if len(elements) != 1 {
+				t.Fatalf("Expected 1 element, got %d", len(elements))
+			}
+			if !strings.HasSuffix(elements[0].AttrPath, ".eza") {
+				t.Errorf("Unexpected attrPath %q", elements[0].AttrPath)
+			}
+		})
+	}
+}
+
+func TestRunPrivilegedUsesSudo(t *testing.T) {
+	origExecCommand := execCommand
+	origGeteuid := geteuid
+	origLookPath := lookPath
+	defer func() {
+		execCommand = origExecCommand
+		geteuid = origGeteuid
+		lookPath = origLookPath
+	}()
+
+	var captured []string
+	execCommand = func(name string, arg ...string) *exec.Cmd {
+		captured = append([]string{name}, arg...)
+		return exec.Command("true")
+	}
+	lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+
+	geteuid = func() int { return 1000 }
+	if err := (DNF{}).Install("ugrep"); err != nil {
+		t.Fatalf("Install() failed: %v", err)
+	}
+	if strings.Join(captured, " ") != "sudo dnf install -y ugrep" {
+		t.Errorf("Unexpected command: %v", captured)
+	}
+
+	geteuid = func() int { return 0 }
+	if err := (DNF{}).Install("ugrep"); err != nil {
+		t.Fatalf("Install() failed: %v", err)
+	}
+	if strings.Join(captured, " ") != "dnf install -y ugrep" {
+		t.Errorf("Unexpected command as root: %v", captured)
+	}
+}
@@ -0,0 +1,285 @@
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// PolicyEnv names an extra policy file, e.g. for trying out a policy. Like the Homebrew
+// prefix file it can only set defaults: the machine-wide policy is always applied on top.
+const PolicyEnv = "BLUEFIN_CLI_POLICY"
+
+// Sources of an effective setting, as reported by Resolve
+const (
+	SourceDefault = "default"
+	SourceUser    = "user"
+	SourcePolicy  = "policy"
+	SourceLocked  = "locked by policy"
+	SourceEnv     = "environment"
+)
+
+// SystemDir is the machine-wide policy directory
+var SystemDir = "/etc/bluefin-cli"
+
+// Policy holds organization defaults and locks, set by an administrator in policy.json.
+// Keys are "<section>.<key>", where the section is "config" (config.json), "shell"
+// (shell.json) or "motd" (motd.json), e.g. "shell.atuin" or "motd.tips-directory".
+type Policy struct {
+	// Settings are defaults that user config overrides, unless the key is locked
+	Settings map[string]json.RawMessage `json:"settings,omitempty"`
+	// Locked keys always take the policy value
+	Locked []string `json:"locked,omitempty"`
+	// AllowedBundles restricts the built-in bundles that can be installed; empty allows all
+	AllowedBundles []string `json:"allowed-bundles,omitempty"`
+
+	// Files are the policy files that were loaded
+	Files []string `json:"-"`
+}
+
+// Value is the effective value of a setting and where it came from
+type Value struct {
+	Key    string          `json:"key"`
+	Value  json.RawMessage `json:"value"`
+	Source string          `json:"source"`
+}
+
+// String renders the value, with strings unquoted
+func (v Value) String() string {
+	var s string
+	if err := json.Unmarshal(v.Value, &s); err == nil {
+		return s
+	}
+	return string(v.Value)
+}
+
+// Paths returns the policy files in the order they are applied: the Homebrew prefix first,
+// then the file named by PolicyEnv, then /etc/bluefin-cli, so machine-wide policy wins
+func Paths() []string {
+	var paths []string
+	if prefix := os.Getenv("HOMEBREW_PREFIX"); prefix != "" {
+		paths = append(paths, filepath.Join(prefix, "etc", "bluefin-cli", "policy.json"))
+	}
+	if path := os.Getenv(PolicyEnv); path != "" {
+		paths = append(paths, path)
+	}
+	return append(paths, systemPath())
+}
+
+func systemPath() string {
+	return filepath.Join(SystemDir, "policy.json")
+}
+
+// Load merges the policy files that exist. A file that cannot be parsed is reported in
+// the error, and the others still apply. Only /etc/bluefin-cli/policy.json can lock keys or
+// restrict bundles, since the other files are writable by users; such entries elsewhere
+// are ignored and reported in the error.
+func Load() (*Policy, error) {
+	merged := &Policy{Settings: make(map[string]json.RawMessage)}
+
+	var errs []string
+	for _, path := range Paths() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				errs = append(errs, err.Error())
+			}
+			continue
+		}
+
+		var p Policy
+		if err := json.Unmarshal(data, &p); err != nil {
+			errs = append(errs, fmt.Sprintf("failed to parse policy %s: %v", path, err))
+			continue
+		}
+
+		for key, value := range p.Settings {
+			merged.Settings[key] = value
+		}
+		if path != systemPath() {
+			if len(p.Locked) > 0 || len(p.AllowedBundles) > 0 {
+				errs = append(errs, fmt.Sprintf("ignoring locked and allowed-bundles in %s: only %s can set them", path, systemPath()))
+			}
+			p.Locked, p.AllowedBundles = nil, nil
+		}
+		merged.Locked = append(merged.Locked, p.Locked...)
+		if len(p.AllowedBundles) > 0 {
+			merged.AllowedBundles = p.AllowedBundles
+		}
+		merged.Files = append(merged.Files, path)
+	}
+
+	if len(errs) > 0 {
+		return merged, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return merged, nil
+}
+
+// IsLocked reports whether a key is locked
+func (p *Policy) IsLocked(key string) bool {
+	for _, locked := range p.Locked {
+		if locked == key {
+			return true
+		}
+	}
+	return false
+}
+
+// BundleAllowed reports whether a built-in bundle may be installed
+func (p *Policy) BundleAllowed(name string) bool {
+	if len(p.AllowedBundles) == 0 {
+		return true
+	}
+	for _, allowed := range p.AllowedBundles {
+		if allowed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// section returns the policy settings of a section, keyed without the section prefix
+func (p *Policy) section(name string) map[string]json.RawMessage {
+	values := make(map[string]json.RawMessage)
+	for key, value := range p.Settings {
+		if k, ok := strings.CutPrefix(key, name+"."); ok {
+			values[k] = value
+		}
+	}
+	return values
+}
+
+func parseObject(data []byte) (map[string]json.RawMessage, error) {
+	object := make(map[string]json.RawMessage)
+	if len(bytes.TrimSpace(data)) == 0 {
+		return object, nil
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+// Merge layers a section's policy around the user's JSON object: policy settings fill in
+// keys the user has not set, and locked keys always take the policy value. user may be nil.
+func (p *Policy) Merge(section string, user []byte) ([]byte, error) {
+	object, err := parseObject(user)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range p.section(section) {
+		if _, set := object[key]; !set || p.IsLocked(section+"."+key) {
+			object[key] = value
+		}
+	}
+	return json.Marshal(object)
+}
+
+// Unmerge reverses Merge before a section is saved: it drops the keys of config that hold
+// the value they get anyway, from the policy or from defaults, so organization defaults are
+// not frozen as user settings. Keys the user's current file sets are kept. defaults and
+// user may be nil.
+func (p *Policy) Unmerge(section string, defaults, user, config []byte) (map[string]json.RawMessage, error) {
+	base, err := parseObject(defaults)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range p.section(section) {
+		base[key] = value
+	}
+	userValues, err := parseObject(user)
+	if err != nil {
+		return nil, err
+	}
+	object, err := parseObject(config)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range object {
+		if _, set := userValues[key]; set {
+			continue
+		}
+		if inherited, ok := base[key]; ok && sameJSON(value, inherited) {
+			delete(object, key)
+		}
+	}
+	return object, nil
+}
+
+// CheckLocked returns an error naming the locked keys that user sets to a value other than
+// the policy's
+func (p *Policy) CheckLocked(section string, user []byte) error {
+	object, err := parseObject(user)
+	if err != nil {
+		return err
+	}
+
+	var conflicts []string
+	for key, value := range p.section(section) {
+		if !p.IsLocked(section + "." + key) {
+			continue
+		}
+		if set, ok := object[key]; ok && !sameJSON(set, value) {
+			conflicts = append(conflicts, section+"."+key)
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return fmt.Errorf("locked by policy: %s", strings.Join(conflicts, ", "))
+	}
+	return nil
+}
+
+func sameJSON(a, b json.RawMessage) bool {
+	var va, vb any
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return bytes.Equal(a, b)
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// Resolve returns the effective value and source of every key of a section that has a
+// built-in default, a user value or a policy setting, sorted by key
+func (p *Policy) Resolve(section string, defaults, user []byte) ([]Value, error) {
+	defaultValues, err := parseObject(defaults)
+	if err != nil {
+		return nil, err
+	}
+	userValues, err := parseObject(user)
+	if err != nil {
+		return nil, err
+	}
+	policyValues := p.section(section)
+
+	keys := make(map[string]bool)
+	for _, values := range []map[string]json.RawMessage{defaultValues, userValues, policyValues} {
+		for key := range values {
+			keys[key] = true
+		}
+	}
+
+	var resolved []Value
+	for key := range keys {
+		full := section + "." + key
+		v := Value{Key: full}
+		if value, ok := policyValues[key]; ok && p.IsLocked(full) {
+			v.Value, v.Source = value, SourceLocked
+		} else if value, ok := userValues[key]; ok {
+			v.Value, v.Source = value, SourceUser
+		} else if value, ok := policyValues[key]; ok {
+			v.Value, v.Source = value, SourcePolicy
+		} else {
+			v.Value, v.Source = defaultValues[key], SourceDefault
+		}
+		resolved = append(resolved, v)
+	}
+	sort.Slice(resolved, func(i, j int) bool { return resolved[i].Key < resolved[j].Key })
+	return resolved, nil
+}
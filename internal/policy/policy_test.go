@@ -0,0 +1,218 @@
+package policy
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writePolicy(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadLayersFiles(t *testing.T) {
+	prefix := t.TempDir()
+	t.Setenv("HOMEBREW_PREFIX", prefix)
+	t.Setenv(PolicyEnv, "")
+	origSystemDir := SystemDir
+	SystemDir = t.TempDir()
+	defer func() { SystemDir = origSystemDir }()
+
+	writePolicy(t, filepath.Join(prefix, "etc", "bluefin-cli", "policy.json"), `{
+		"settings": {"shell.atuin": true, "motd.tips-directory": "/opt/tips"}
+	}`)
+	writePolicy(t, filepath.Join(SystemDir, "policy.json"), `{
+		"settings": {"shell.atuin": false},
+		"locked": ["shell.atuin"],
+		"allowed-bundles": ["cli", "k8s"]
+	}`)
+
+	p, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(p.Files) != 2 {
+		t.Errorf("Expected 2 policy files, got %v", p.Files)
+	}
+	if string(p.Settings["shell.atuin"]) != "false" {
+		t.Errorf("Expected /etc policy to win, got %s", p.Settings["shell.atuin"])
+	}
+	if string(p.Settings["motd.tips-directory"]) != `"/opt/tips"` {
+		t.Errorf("Expected Homebrew prefix setting to be kept, got %s", p.Settings["motd.tips-directory"])
+	}
+	if !p.IsLocked("shell.atuin") || p.IsLocked("motd.tips-directory") {
+		t.Errorf("Unexpected locks: %v", p.Locked)
+	}
+	if !p.BundleAllowed("k8s") || p.BundleAllowed("ai") {
+		t.Errorf("Unexpected allowed bundles: %v", p.AllowedBundles)
+	}
+}
+
+func TestLoadKeepsSystemPolicy(t *testing.T) {
+	prefix := t.TempDir()
+	t.Setenv("HOMEBREW_PREFIX", prefix)
+	origSystemDir := SystemDir
+	SystemDir = t.TempDir()
+	defer func() { SystemDir = origSystemDir }()
+
+	// Files users can write cannot lock keys or lift the bundle restriction
+	envPath := filepath.Join(t.TempDir(), "policy.json")
+	t.Setenv(PolicyEnv, envPath)
+	writePolicy(t, envPath, `{
+		"settings": {"shell.atuin": true, "shell.eza": true},
+		"locked": ["shell.eza"],
+		"allowed-bundles": ["ai"]
+	}`)
+	writePolicy(t, filepath.Join(prefix, "etc", "bluefin-cli", "policy.json"), `{"locked": ["shell.bat"]}`)
+	writePolicy(t, filepath.Join(SystemDir, "policy.json"), `{
+		"settings": {"shell.atuin": false},
+		"locked": ["shell.atuin"],
+		"allowed-bundles": ["cli"]
+	}`)
+
+	p, err := Load()
+	if err == nil {
+		t.Error("Expected the ignored locks to be reported")
+	}
+	if len(p.Files) != 3 {
+		t.Errorf("Expected 3 policy files, got %v", p.Files)
+	}
+	if !p.IsLocked("shell.atuin") || string(p.Settings["shell.atuin"]) != "false" {
+		t.Errorf("Expected the /etc lock to apply, got %v %s", p.Locked, p.Settings["shell.atuin"])
+	}
+	if p.IsLocked("shell.eza") || p.IsLocked("shell.bat") {
+		t.Errorf("Expected locks outside /etc to be ignored, got %v", p.Locked)
+	}
+	if string(p.Settings["shell.eza"]) != "true" {
+		t.Errorf("Expected the extra file's defaults to apply, got %s", p.Settings["shell.eza"])
+	}
+	if p.BundleAllowed("ai") || !p.BundleAllowed("cli") {
+		t.Errorf("Unexpected allowed bundles: %v", p.AllowedBundles)
+	}
+}
+
+func TestLoadReportsBrokenFile(t *testing.T) {
+	t.Setenv("HOMEBREW_PREFIX", "")
+	origSystemDir := SystemDir
+	SystemDir = t.TempDir()
+	defer func() { SystemDir = origSystemDir }()
+
+	path := filepath.Join(t.TempDir(), "policy.json")
+	t.Setenv(PolicyEnv, path)
+	writePolicy(t, path, `{not json`)
+
+	p, err := Load()
+	if err == nil {
+		t.Error("Expected a parse error")
+	}
+	if p == nil || !p.BundleAllowed("ai") {
+		t.Error("Expected an empty policy alongside the error")
+	}
+}
+
+func testPolicy() *Policy {
+	return &Policy{
+		Settings: map[string]json.RawMessage{
+			"shell.atuin":         json.RawMessage(`false`),
+			"shell.eza":           json.RawMessage(`true`),
+			"motd.tips-directory": json.RawMessage(`"/opt/tips"`),
+		},
+		Locked: []string{"shell.atuin"},
+	}
+}
+
+func TestMerge(t *testing.T) {
+	merged, err := testPolicy().Merge("shell", []byte(`{"atuin": true, "eza": false, "bat": true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]bool
+	if err := json.Unmarshal(merged, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{"atuin": false, "eza": false, "bat": true}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %v, want %v", key, got[key], value)
+		}
+	}
+
+	// Without a user file the policy settings fill in
+	merged, err = testPolicy().Merge("shell", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(merged, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got["eza"] {
+		t.Error("Expected the policy default for eza")
+	}
+}
+
+func TestCheckLocked(t *testing.T) {
+	p := testPolicy()
+	if err := p.CheckLocked("shell", []byte(`{"atuin": false, "eza": false}`)); err != nil {
+		t.Errorf("Expected no conflict, got %v", err)
+	}
+	if err := p.CheckLocked("shell", []byte(`{"atuin": true}`)); err == nil {
+		t.Error("Expected changing a locked key to fail")
+	}
+}
+
+func TestUnmerge(t *testing.T) {
+	object, err := testPolicy().Unmerge("shell",
+		[]byte(`{"atuin": true, "eza": false, "bat": true, "zoxide": true}`),
+		[]byte(`{"zoxide": true}`),
+		[]byte(`{"atuin": false, "eza": true, "bat": true, "zoxide": true, "fzf": false}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Policy and built-in defaults are dropped, keys from the user's file and changes kept
+	for _, key := range []string{"atuin", "eza", "bat"} {
+		if _, ok := object[key]; ok {
+			t.Errorf("Expected %s to be dropped, got %s", key, object[key])
+		}
+	}
+	for _, key := range []string{"zoxide", "fzf"} {
+		if _, ok := object[key]; !ok {
+			t.Errorf("Expected %s to be kept", key)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	values, err := testPolicy().Resolve("shell",
+		[]byte(`{"atuin": true, "eza": false, "bat": true, "zoxide": true}`),
+		[]byte(`{"bat": false, "atuin": true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"shell.atuin":  SourceLocked,
+		"shell.bat":    SourceUser,
+		"shell.eza":    SourcePolicy,
+		"shell.zoxide": SourceDefault,
+	}
+	if len(values) != len(want) {
+		t.Fatalf("Unexpected values: %+v", values)
+	}
+	for _, v := range values {
+		if v.Source != want[v.Key] {
+			t.Errorf("%s: source = %s, want %s", v.Key, v.Source, want[v.Key])
+		}
+	}
+	if values[0].Key != "shell.atuin" || values[0].String() != "false" {
+		t.Errorf("Unexpected atuin value: %+v", values[0])
+	}
+}
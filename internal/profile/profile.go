@@ -0,0 +1,167 @@
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hanthor/bluefin-cli/internal/env"
+	"github.com/hanthor/bluefin-cli/internal/motd"
+	"github.com/hanthor/bluefin-cli/internal/shell"
+	"github.com/hanthor/bluefin-cli/internal/starship"
+)
+
+// Profile is a portable snapshot of a bluefin-cli setup: the shell tools, MOTD and Starship
+// settings, and optionally a Brewfile of the installed packages
+type Profile struct {
+	Name           string       `json:"name,omitempty"`
+	Created        time.Time    `json:"created"`
+	Shell          shell.Config `json:"shell"`
+	Motd           motd.Config  `json:"motd"`
+	StarshipPreset string       `json:"starship-preset,omitempty"`
+	// StarshipConfig is the contents of starship.toml, so hand edits to a preset survive
+	StarshipConfig string `json:"starship-config,omitempty"`
+	Brewfile       string `json:"brewfile,omitempty"`
+}
+
+// Current returns the settings in use, without a Brewfile
+func Current(shellName string) (*Profile, error) {
+	shellCfg, err := shell.LoadConfig(shellName)
+	if err != nil {
+		return nil, err
+	}
+	motdCfg, err := motd.LoadConfig()
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to load MOTD config: %w", err)
+	}
+
+	return &Profile{
+		Created:        time.Now(),
+		Shell:          *shellCfg,
+		Motd:           motdCfg,
+		StarshipPreset: starship.Preset(),
+		StarshipConfig: starship.ReadConfig(),
+	}, nil
+}
+
+// Read loads a profile file
+func Read(path string) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var p Profile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse profile %s: %w", path, err)
+	}
+	if p.Shell == nil {
+		return nil, fmt.Errorf("%s is not a bluefin-cli profile", path)
+	}
+	return &p, nil
+}
+
+// Write saves a profile file
+func Write(path string, p *Profile) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write profile: %w", err)
+	}
+	defer f.Close()
+
+	if err := Encode(f, p); err != nil {
+		return fmt.Errorf("failed to write profile: %w", err)
+	}
+	return f.Close()
+}
+
+// Encode writes a profile as indented JSON
+func Encode(w io.Writer, p *Profile) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(p)
+}
+
+// Apply saves the profile's shell and MOTD configs and restores its starship.toml, or applies
+// its Starship preset for profiles without one. Locked settings and the preset are checked
+// before anything is written, and every file is restored if a later step fails.
+func (p *Profile) Apply() error {
+	writeConfig := p.StarshipConfig != "" && p.StarshipConfig != starship.ReadConfig()
+	applyPreset := p.StarshipConfig == "" && p.StarshipPreset != "" && p.StarshipPreset != starship.Preset()
+	if applyPreset {
+		if err := starship.CheckPreset(p.StarshipPreset); err != nil {
+			return err
+		}
+	}
+	if err := shell.CheckConfig(&p.Shell); err != nil {
+		return err
+	}
+	if err := motd.CheckConfig(p.Motd); err != nil {
+		return err
+	}
+
+	configDir, err := env.GetConfigDir()
+	if err != nil {
+		return err
+	}
+	starshipConfig, err := starship.ConfigPath()
+	if err != nil {
+		return err
+	}
+	var restores []func()
+	for _, file := range []string{
+		filepath.Join(configDir, "shell.json"),
+		filepath.Join(configDir, "motd.json"),
+		filepath.Join(configDir, "starship.json"),
+		starshipConfig,
+	} {
+		restore, err := backupFile(file)
+		if err != nil {
+			return err
+		}
+		restores = append(restores, restore)
+	}
+	rollback := func(err error) error {
+		for _, restore := range restores {
+			restore()
+		}
+		return err
+	}
+
+	if err := shell.SaveConfig(&p.Shell); err != nil {
+		return rollback(err)
+	}
+	if err := motd.SaveConfig(p.Motd); err != nil {
+		return rollback(fmt.Errorf("failed to save MOTD config: %w", err))
+	}
+	if writeConfig {
+		if err := starship.WriteConfig(p.StarshipConfig, p.StarshipPreset); err != nil {
+			return rollback(err)
+		}
+	} else if applyPreset {
+		if err := starship.ApplyTheme(p.StarshipPreset); err != nil {
+			return rollback(err)
+		}
+	}
+	return nil
+}
+
+// backupFile reads a file and returns a func writing it back with its mode, or removing
+// the file if it didn't exist
+func backupFile(file string) (func(), error) {
+	info, err := os.Stat(file)
+	if os.IsNotExist(err) {
+		return func() { os.Remove(file) }, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return func() { env.WriteFileAtomic(file, data, info.Mode().Perm()) }, nil
+}
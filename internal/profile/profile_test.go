@@ -0,0 +1,140 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hanthor/bluefin-cli/internal/motd"
+	"github.com/hanthor/bluefin-cli/internal/policy"
+	"github.com/hanthor/bluefin-cli/internal/shell"
+)
+
+func TestWriteReadApply(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("HOMEBREW_PREFIX", "")
+
+	cfg := motd.DefaultConfig()
+	cfg.DefaultTheme = "dracula"
+	p := &Profile{
+		Name:     "workstation",
+		Shell:    shell.Config{"eza": true, "atuin": false, "motd": false},
+		Motd:     cfg,
+		Brewfile: "brew \"kubectl\" # bundle: k8s\n",
+	}
+
+	path := filepath.Join(t.TempDir(), "profile.json")
+	if err := Write(path, p); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	read, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if read.Name != "workstation" || read.Brewfile != p.Brewfile || read.Motd.DefaultTheme != "dracula" {
+		t.Errorf("Unexpected profile: %+v", read)
+	}
+
+	if err := read.Apply(); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	current, err := Current("bash")
+	if err != nil {
+		t.Fatalf("Current failed: %v", err)
+	}
+	if current.Shell.IsEnabled("atuin") || !current.Shell.IsEnabled("eza") || current.Shell.IsEnabled("motd") {
+		t.Errorf("Shell config not applied: %v", current.Shell)
+	}
+	if current.Motd.DefaultTheme != "dracula" {
+		t.Errorf("MOTD config not applied: %+v", current.Motd)
+	}
+}
+
+func TestReadRejectsOtherJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "other.json")
+	if err := Write(path, &Profile{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Read(path); err == nil {
+		t.Error("Expected an error for a file without shell settings")
+	}
+}
+
+func TestApplyChecksPolicyFirst(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("HOMEBREW_PREFIX", "")
+
+	origSystemDir := policy.SystemDir
+	policy.SystemDir = t.TempDir()
+	t.Cleanup(func() { policy.SystemDir = origSystemDir })
+	if err := os.WriteFile(filepath.Join(policy.SystemDir, "policy.json"), []byte(`{
+		"settings": {"motd.default-theme": "slate"},
+		"locked": ["motd.default-theme"]
+	}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := shell.SaveConfig(&shell.Config{"eza": false}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := motd.DefaultConfig()
+	cfg.DefaultTheme = "dracula"
+	p := &Profile{Shell: shell.Config{"eza": true}, Motd: cfg}
+	if err := p.Apply(); err == nil {
+		t.Fatal("Expected the locked MOTD theme to be rejected")
+	}
+
+	current, err := Current("bash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Shell.IsEnabled("eza") {
+		t.Error("Expected the shell config to be left alone")
+	}
+}
+
+func TestApplyStarshipConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("HOMEBREW_PREFIX", "")
+	starshipToml := filepath.Join(home, ".config", "starship.toml")
+
+	work := &Profile{Shell: shell.Config{"eza": true}, Motd: motd.DefaultConfig(), StarshipConfig: "format = \"$all\"\n"}
+	if err := work.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(starshipToml); string(data) != work.StarshipConfig {
+		t.Errorf("Expected the profile's starship.toml, got %q", data)
+	}
+
+	// A failed step leaves every file as it was, including a hand-edited starship.toml. This
+	// starship knows the preset but fails to write it.
+	if err := os.WriteFile(starshipToml, []byte("# my edits\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	bin := t.TempDir()
+	script := "#!/bin/sh\nif [ \"$2\" = --list ]; then echo broken; exit 0; fi\nexit 1\n"
+	if err := os.WriteFile(filepath.Join(bin, "starship"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	cfg := motd.DefaultConfig()
+	cfg.DefaultTheme = "dracula"
+	demo := &Profile{Shell: shell.Config{"eza": false}, Motd: cfg, StarshipPreset: "broken"}
+	if err := demo.Apply(); err == nil {
+		t.Fatal("Expected the preset to fail")
+	}
+	if data, _ := os.ReadFile(starshipToml); string(data) != "# my edits\n" {
+		t.Errorf("Expected starship.toml to be left alone, got %q", data)
+	}
+	current, err := Current("bash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !current.Shell.IsEnabled("eza") || current.Motd.DefaultTheme == "dracula" {
+		t.Errorf("Expected the shell and MOTD configs to be restored, got %v, %s", current.Shell, current.Motd.DefaultTheme)
+	}
+}
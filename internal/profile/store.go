@@ -0,0 +1,189 @@
+package profile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hanthor/bluefin-cli/internal/env"
+	"github.com/hanthor/bluefin-cli/internal/shell"
+)
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Dir returns the directory named profiles are stored in
+func Dir() (string, error) {
+	dir, err := env.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "profiles"), nil
+}
+
+func path(name string) (string, error) {
+	if !validName.MatchString(name) {
+		return "", fmt.Errorf("invalid profile name %q: use letters, digits, '.', '-' and '_'", name)
+	}
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// Create saves the current settings as a named profile
+func Create(name, shellName string, overwrite bool) (*Profile, error) {
+	file, err := path(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(file); err == nil && !overwrite {
+		return nil, fmt.Errorf("profile %s already exists", name)
+	}
+
+	p, err := Current(shellName)
+	if err != nil {
+		return nil, err
+	}
+	p.Name = name
+
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create profile directory: %w", err)
+	}
+	return p, Write(file, p)
+}
+
+// Load reads a named profile
+func Load(name string) (*Profile, error) {
+	file, err := path(name)
+	if err != nil {
+		return nil, err
+	}
+	p, err := Read(file)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("profile %s does not exist", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.Name = name
+	return p, nil
+}
+
+// List returns the named profiles, sorted by name
+func List() ([]Profile, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var profiles []Profile
+	for _, m := range matches {
+		p, err := Load(strings.TrimSuffix(filepath.Base(m), ".json"))
+		if err != nil {
+			continue
+		}
+		profiles = append(profiles, *p)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles, nil
+}
+
+// Delete removes a named profile
+func Delete(name string) error {
+	file, err := path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(file); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("profile %s does not exist", name)
+		}
+		return err
+	}
+	if Active() == name {
+		setActive("")
+	}
+	return nil
+}
+
+// Use applies a named profile and returns the shells with bluefin-cli integration that
+// need a restart to pick up the change
+func Use(name, shellName string) ([]string, error) {
+	p, err := Load(name)
+	if err != nil {
+		return nil, err
+	}
+	current, err := Current(shellName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.Apply(); err != nil {
+		return nil, err
+	}
+	if err := setActive(name); err != nil {
+		return nil, err
+	}
+
+	if reflect.DeepEqual(current.Shell, p.Shell) && reflect.DeepEqual(current.Motd, p.Motd) &&
+		(p.StarshipPreset == "" || p.StarshipPreset == current.StarshipPreset) &&
+		(p.StarshipConfig == "" || p.StarshipConfig == current.StarshipConfig) {
+		return nil, nil
+	}
+
+	var restart []string
+	for sh, enabled := range shell.CheckStatus() {
+		if enabled {
+			restart = append(restart, sh)
+		}
+	}
+	sort.Strings(restart)
+	return restart, nil
+}
+
+func activePath() (string, error) {
+	dir, err := env.GetStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "profile"), nil
+}
+
+// Active returns the name of the profile last switched to, or "" if none
+func Active() string {
+	file, err := activePath()
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func setActive(name string) error {
+	if _, err := env.EnsureStateDir(); err != nil {
+		return err
+	}
+	file, err := activePath()
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return env.WriteFileAtomic(file, []byte(name+"\n"), 0644)
+}
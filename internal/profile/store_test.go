@@ -0,0 +1,100 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hanthor/bluefin-cli/internal/motd"
+	"github.com/hanthor/bluefin-cli/internal/shell"
+)
+
+func setupHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("HOMEBREW_PREFIX", "")
+	t.Setenv("XDG_STATE_HOME", filepath.Join(home, ".local", "state"))
+	return home
+}
+
+func TestCreateUseListDelete(t *testing.T) {
+	home := setupHome(t)
+	if err := os.WriteFile(filepath.Join(home, ".bashrc"), []byte("# bluefin-cli shell-config\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// "work" is the full setup, "demo" a minimal one
+	if err := shell.SaveConfig(&shell.Config{"eza": true, "atuin": true, "motd": true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Create("work", "bash", false); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := Create("work", "bash", false); err == nil {
+		t.Error("Expected an error when the profile exists")
+	}
+
+	if err := shell.SaveConfig(&shell.Config{"eza": false, "atuin": false, "motd": false}); err != nil {
+		t.Fatal(err)
+	}
+	demoMotd := motd.DefaultConfig()
+	demoMotd.DefaultTheme = "light"
+	if err := motd.SaveConfig(demoMotd); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Create("demo", "bash", false); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	profiles, err := List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 2 || profiles[0].Name != "demo" || profiles[1].Name != "work" {
+		t.Fatalf("Unexpected profiles: %+v", profiles)
+	}
+
+	restart, err := Use("work", "bash")
+	if err != nil {
+		t.Fatalf("Use failed: %v", err)
+	}
+	if !reflect.DeepEqual(restart, []string{"bash"}) {
+		t.Errorf("Expected bash to need a restart, got %v", restart)
+	}
+	if Active() != "work" {
+		t.Errorf("Active() = %q, want work", Active())
+	}
+	cfg, err := shell.LoadConfig("bash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.IsEnabled("atuin") || !cfg.IsEnabled("motd") {
+		t.Errorf("Work profile not applied: %v", *cfg)
+	}
+
+	// Switching to the profile in use changes nothing
+	if restart, err := Use("work", "bash"); err != nil || len(restart) != 0 {
+		t.Errorf("Expected no restart, got %v, %v", restart, err)
+	}
+
+	if err := Delete("work"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if Active() != "" {
+		t.Errorf("Expected no active profile after deleting it, got %q", Active())
+	}
+	if _, err := Load("work"); err == nil {
+		t.Error("Expected an error loading a deleted profile")
+	}
+}
+
+func TestInvalidProfileName(t *testing.T) {
+	setupHome(t)
+	for _, name := range []string{"", "../etc", ".hidden", "a/b"} {
+		if _, err := Create(name, "bash", false); err == nil {
+			t.Errorf("Expected %q to be rejected", name)
+		}
+	}
+}
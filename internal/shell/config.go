@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/hanthor/bluefin-cli/internal/env"
+	"github.com/hanthor/bluefin-cli/internal/policy"
 )
 
 type Config map[string]bool
@@ -34,6 +35,20 @@ func (c Config) SetEnabled(toolName string, enabled bool) {
 	c[key] = enabled
 }
 
+// MotdKey is the shell.json key that turns the MOTD on or off for one shell, e.g. "motd-zsh".
+// It overrides "motd", which applies to every shell.
+func MotdKey(shell string) string {
+	return "motd-" + shell
+}
+
+// MotdEnabled reports whether the init script for shell shows the MOTD
+func (c Config) MotdEnabled(shell string) bool {
+	if enabled, ok := c[MotdKey(shell)]; ok {
+		return enabled
+	}
+	return c.IsEnabled("motd")
+}
+
 func DefaultConfig(shell string) *Config {
 	cfg := make(Config)
 
@@ -51,29 +66,69 @@ func DefaultConfig(shell string) *Config {
 	return &cfg
 }
 
+// LoadConfig reads shell.json, falling back to the defaults for shell when it does not exist.
+// Tool settings from the policy fill in unset keys, and locked ones override the file.
 func LoadConfig(shell string) (*Config, error) {
-	configPath, err := getConfigPath()
+	data, err := readConfigFile()
 	if err != nil {
 		return nil, err
 	}
 
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return DefaultConfig(shell), nil
+	config := make(Config)
+	if data == nil {
+		config = *DefaultConfig(shell)
+	}
+
+	pol, _ := policy.Load()
+	merged, err := pol.Merge("shell", data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := json.Unmarshal(merged, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	// A locked MOTD setting applies to every shell
+	if pol.IsLocked("shell.motd") {
+		for _, sh := range Shells {
+			delete(config, MotdKey(sh))
+		}
+	}
+
+	return &config, nil
+}
+
+func readConfigFile() ([]byte, error) {
+	configPath, err := getConfigPath()
+	if err != nil {
+		return nil, err
 	}
 
 	content, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config: %w", err)
 	}
+	return content, nil
+}
 
-	var config Config
-	if err := json.Unmarshal(content, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config: %w", err)
+// Effective returns every shell setting with its effective value and where it came from
+func Effective(shell string) ([]policy.Value, error) {
+	defaults, err := json.Marshal(DefaultConfig(shell))
+	if err != nil {
+		return nil, err
+	}
+	data, err := readConfigFile()
+	if err != nil {
+		return nil, err
 	}
 
-	return &config, nil
+	pol, _ := policy.Load()
+	return pol.Resolve("shell", defaults, data)
 }
 
+// SaveConfig writes shell.json, leaving out settings that only come from the policy
 func SaveConfig(config *Config) error {
 	configPath, err := getConfigPath()
 	if err != nil {
@@ -85,18 +140,52 @@ func SaveConfig(config *Config) error {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	content, err := json.MarshalIndent(config, "", "  ")
+	content, err := json.Marshal(config)
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	if err := os.WriteFile(configPath, content, 0644); err != nil {
+	if err := checkLocked(content); err != nil {
+		return err
+	}
+
+	// Keys holding the policy value are left for the policy to fill in. Built-in defaults are
+	// kept, since they depend on the shell and shell.json is shared by all of them.
+	existing, err := readConfigFile()
+	if err != nil {
+		return err
+	}
+	pol, _ := policy.Load()
+	object, err := pol.Unmerge("shell", nil, existing, content)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	content, err = json.MarshalIndent(object, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	if err := env.WriteFileAtomic(configPath, content, 0644); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
 	}
 
 	return nil
 }
 
+// CheckConfig returns the error SaveConfig would give for settings locked by policy
+func CheckConfig(config *Config) error {
+	content, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return checkLocked(content)
+}
+
+func checkLocked(content []byte) error {
+	pol, _ := policy.Load()
+	return pol.CheckLocked("shell", content)
+}
+
 func getConfigPath() (string, error) {
 	dir, err := env.GetConfigDir()
 	if err != nil {
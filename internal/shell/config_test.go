@@ -2,7 +2,10 @@ package shell
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/hanthor/bluefin-cli/internal/policy"
 )
 
 func TestConfigData(t *testing.T) {
@@ -37,3 +40,102 @@ func TestConfigData(t *testing.T) {
 
 }
 
+
+func TestConfigPolicy(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	t.Setenv("HOMEBREW_PREFIX", "")
+
+	setSystemPolicyDir(t)
+	policyPath := filepath.Join(policy.SystemDir, "policy.json")
+	if err := os.WriteFile(policyPath, []byte(`{
+		"settings": {"shell.atuin": true, "shell.carapace": true, "shell.bat": false},
+		"locked": ["shell.atuin"]
+	}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without shell.json, policy settings replace the built-in defaults
+	cfg, err := LoadConfig("bash")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if !cfg.IsEnabled("Atuin") || !cfg.IsEnabled("Carapace") {
+		t.Errorf("Expected policy defaults, got %v", *cfg)
+	}
+
+	cfg.SetEnabled("Carapace", false)
+	if err := SaveConfig(cfg); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	cfg.SetEnabled("Atuin", false)
+	if err := SaveConfig(cfg); err == nil {
+		t.Error("Expected disabling a locked tool to fail")
+	}
+
+	cfg, err = LoadConfig("bash")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if !cfg.IsEnabled("Atuin") || cfg.IsEnabled("Carapace") {
+		t.Errorf("Expected atuin locked on and carapace overridden off, got %v", *cfg)
+	}
+
+	// Saving left the policy default for bat to the policy
+	values, err := Effective("bash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range values {
+		if v.Key == "shell.bat" && v.Source != policy.SourcePolicy {
+			t.Errorf("Expected shell.bat to come from the policy after saving, got %s", v.Source)
+		}
+	}
+}
+
+func TestMotdEnabled(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	t.Setenv("HOMEBREW_PREFIX", "")
+
+	cfg := DefaultConfig("bash")
+	if !cfg.MotdEnabled("bash") {
+		t.Error("Expected the MOTD to be enabled by default")
+	}
+	(*cfg)[MotdKey("fish")] = false
+	if !cfg.MotdEnabled("bash") || cfg.MotdEnabled("fish") {
+		t.Errorf("Expected the MOTD off for fish only, got %v", *cfg)
+	}
+	cfg.SetEnabled("motd", false)
+	(*cfg)[MotdKey("zsh")] = true
+	if cfg.MotdEnabled("bash") || !cfg.MotdEnabled("zsh") {
+		t.Errorf("Expected the MOTD on for zsh only, got %v", *cfg)
+	}
+	if err := SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	// A locked MOTD setting wins over the per-shell ones
+	setSystemPolicyDir(t)
+	policyPath := filepath.Join(policy.SystemDir, "policy.json")
+	if err := os.WriteFile(policyPath, []byte(`{"settings": {"shell.motd": true}, "locked": ["shell.motd"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig("bash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sh := range Shells {
+		if !cfg.MotdEnabled(sh) {
+			t.Errorf("Expected the locked MOTD setting for %s, got %v", sh, *cfg)
+		}
+	}
+}
+
+// setSystemPolicyDir points the machine-wide policy at an empty temporary directory
+func setSystemPolicyDir(t *testing.T) {
+	t.Helper()
+	origSystemDir := policy.SystemDir
+	policy.SystemDir = t.TempDir()
+	t.Cleanup(func() { policy.SystemDir = origSystemDir })
+}
@@ -6,10 +6,15 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/hanthor/bluefin-cli/internal/config"
+	"github.com/hanthor/bluefin-cli/internal/httpclient"
+	"github.com/hanthor/bluefin-cli/internal/ledger"
+	"github.com/hanthor/bluefin-cli/internal/pkgmgr"
 )
 
 // InstallTools iterates through the config and installs enabled tools
@@ -25,50 +30,94 @@ func InstallTools(cfg *Config) {
 		}
 	}
 
-	if cfg.IsEnabled("Motd") {
-		if _, err := exec.LookPath("glow"); err != nil {
-			needsInstall = true
-		}
-	}
-
 	if !needsInstall {
 		return
 	}
 
-	// Ensure Homebrew is available
-	if err := ensureHomebrew(); err != nil {
+	managers, err := packageManagers()
+	if err != nil {
 		fmt.Println(errorStyle.Render(fmt.Sprintf("Skipping tool installation: %v", err)))
 		return
 	}
 
 	for _, tool := range Tools {
 		if cfg.IsEnabled(tool.Name) {
-			if err := ensureTool(tool.Binary, tool.Pkg); err != nil {
+			if err := ensureTool(managers, tool); err != nil {
 				fmt.Println(errorStyle.Render(fmt.Sprintf("Warning: Failed to install %s: %v", tool.Pkg, err)))
 			}
 		}
 	}
+}
+
+// PrunableTools returns the ledger entries of tools bluefin-cli installed that cfg no longer enables
+func PrunableTools(cfg *Config) ([]ledger.Entry, error) {
+	installed, err := ledger.Installed()
+	if err != nil {
+		return nil, err
+	}
 
-	if cfg.IsEnabled("Motd") {
-		if err := ensureTool("glow", "glow"); err != nil {
-			fmt.Println(errorStyle.Render(fmt.Sprintf("Warning: Failed to install glow: %v", err)))
+	return ledger.Filter(installed, func(e ledger.Entry) bool {
+		if e.Tool == "" {
+			return false
 		}
+		// Glow rendered the MOTD before it was rendered natively
+		if e.Tool == "Glow" {
+			return true
+		}
+		return !cfg.IsEnabled(e.Tool)
+	}), nil
+}
+
+// packageManagers returns the usable backends in the configured preference order.
+// Homebrew is only offered for installation when no preferred backend is available.
+func packageManagers() ([]pkgmgr.PackageManager, error) {
+	settings, err := config.Load()
+	if err != nil {
+		fmt.Println(errorStyle.Render(fmt.Sprintf("Warning: %v, using default package managers", err)))
+	}
+
+	// Homebrew is often installed but missing from PATH in non-login shells
+	if slices.Contains(settings.PackageManagers, "brew") {
+		addHomebrewToPath()
+	}
+
+	if managers := pkgmgr.Detected(settings.PackageManagers); len(managers) > 0 {
+		return managers, nil
 	}
+
+	if !slices.Contains(settings.PackageManagers, "brew") {
+		return nil, fmt.Errorf("none of the configured package managers are available: %s", strings.Join(settings.PackageManagers, ", "))
+	}
+
+	if err := ensureHomebrew(); err != nil {
+		return nil, err
+	}
+
+	return pkgmgr.Detected(settings.PackageManagers), nil
 }
 
-func ensureHomebrew() error {
+var homebrewPaths = []string{"/home/linuxbrew/.linuxbrew/bin/brew", "/opt/homebrew/bin/brew", "/usr/local/bin/brew"}
+
+// addHomebrewToPath appends the first Homebrew found in a standard location to PATH
+func addHomebrewToPath() bool {
 	if _, err := exec.LookPath("brew"); err == nil {
-		return nil
+		return true
 	}
 
-	commonPaths := []string{"/home/linuxbrew/.linuxbrew/bin/brew", "/opt/homebrew/bin/brew", "/usr/local/bin/brew"}
-	for _, p := range commonPaths {
+	for _, p := range homebrewPaths {
 		if _, err := os.Stat(p); err == nil {
 			path := os.Getenv("PATH")
 			os.Setenv("PATH", path+string(os.PathListSeparator)+filepath.Dir(p))
-			return nil
+			return true
 		}
 	}
+	return false
+}
+
+func ensureHomebrew() error {
+	if addHomebrewToPath() {
+		return nil
+	}
 
 	fmt.Println(infoStyle.Render("Homebrew is missing. It is required to install enabled components."))
 	var install bool
@@ -86,7 +135,7 @@ func ensureHomebrew() error {
 
 	fmt.Println(infoStyle.Render("⬇️  Installing Homebrew..."))
 	
-	cmd := exec.Command("/bin/bash", "-c", "curl -fsSL https://raw.githubusercontent.com/Homebrew/install/HEAD/install.sh | bash")
+	cmd := exec.Command("/bin/bash", "-c", httpclient.CurlCommand("https://raw.githubusercontent.com/Homebrew/install/HEAD/install.sh", "-fsSL")+" | bash")
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -94,36 +143,43 @@ func ensureHomebrew() error {
 		return fmt.Errorf("failed to install homebrew: %w", err)
 	}
 
-	for _, p := range commonPaths {
-		if _, err := os.Stat(p); err == nil {
-			path := os.Getenv("PATH")
-			os.Setenv("PATH", path+string(os.PathListSeparator)+filepath.Dir(p))
-			fmt.Println(successStyle.Render("✓ Homebrew installed and added to PATH for this session."))
-			return nil
-		}
+	if addHomebrewToPath() {
+		fmt.Println(successStyle.Render("✓ Homebrew installed and added to PATH for this session."))
+		return nil
 	}
 	
 	return fmt.Errorf("homebrew installed but not found in expected locations")
 }
 
-func ensureTool(binary, pkg string) error {
-	if _, err := exec.LookPath(binary); err == nil {
+func ensureTool(managers []pkgmgr.PackageManager, tool Tool) error {
+	if _, err := exec.LookPath(tool.Binary); err == nil {
 		return nil
 	}
 
-	if _, err := exec.LookPath("brew"); err != nil {
-		return fmt.Errorf("brew not found")
+	var lastErr error
+	for _, pm := range managers {
+		pkg, ok := tool.PackageFor(pm.Name())
+		if !ok {
+			continue
+		}
+
+		fmt.Println(infoStyle.Render(fmt.Sprintf("⬇️  Installing %s via %s...", pkg, pm.Name())))
+		if err := pm.Install(pkg); err != nil {
+			lastErr = fmt.Errorf("failed to install %s: %w", pkg, err)
+			continue
+		}
+		fmt.Println(successStyle.Render(fmt.Sprintf("✓ %s installed successfully!", pkg)))
+		version, _ := pm.Version(pkg)
+		if err := ledger.Record(ledger.Entry{Action: ledger.ActionInstall, Package: pkg, Backend: pm.Name(), Version: version, Tool: tool.Name}); err != nil {
+			fmt.Println(errorStyle.Render(fmt.Sprintf("Warning: failed to record %s: %v", pkg, err)))
+		}
+		return nil
 	}
 
-	fmt.Println(infoStyle.Render(fmt.Sprintf("⬇️  Installing %s via Homebrew...", pkg)))
-	cmd := exec.Command("brew", "install", pkg)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to install %s: %w", pkg, err)
+	if lastErr != nil {
+		return lastErr
 	}
-	fmt.Println(successStyle.Render(fmt.Sprintf("✓ %s installed successfully!", pkg)))
-	return nil
+	return fmt.Errorf("no configured package manager provides %s", tool.Name)
 }
 
 //go:embed resources/shell.sh
@@ -138,6 +194,9 @@ var (
 	infoStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("12"))
 )
 
+// Shells are the shells bluefin-cli integrates with
+var Shells = []string{"bash", "zsh", "fish"}
+
 const shellMaker = "# bluefin-cli shell-config"
 const blingMarker = "# bluefin-cli bling"
 
@@ -264,20 +323,31 @@ func Init(shell string, config *Config) (string, error) {
 	return sb.String(), nil
 }
 
+// RcFile returns the startup file of shell that bluefin-cli adds its integration to
+func RcFile(shell string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	switch shell {
+	case "bash":
+		return filepath.Join(home, ".bashrc"), nil
+	case "zsh":
+		return filepath.Join(home, ".zshrc"), nil
+	case "fish":
+		return filepath.Join(home, ".config/fish/config.fish"), nil
+	}
+	return "", fmt.Errorf("unsupported shell: %s", shell)
+}
+
 func CheckStatus() map[string]bool {
 	status := make(map[string]bool)
-	shells := []string{"bash", "zsh", "fish"}
-	home, _ := os.UserHomeDir()
-
-	for _, shell := range shells {
-		var configFile string
-		switch shell {
-		case "bash":
-			configFile = filepath.Join(home, ".bashrc")
-		case "zsh":
-			configFile = filepath.Join(home, ".zshrc")
-		case "fish":
-			configFile = filepath.Join(home, ".config/fish/config.fish")
+
+	for _, shell := range Shells {
+		configFile, err := RcFile(shell)
+		if err != nil {
+			status[shell] = false
+			continue
 		}
 
 		content, err := os.ReadFile(configFile)
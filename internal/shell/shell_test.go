@@ -5,9 +5,15 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/hanthor/bluefin-cli/internal/config"
 )
 
 func TestToggle(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	// Keep the test from installing tools through a real package manager
+	t.Setenv(config.PackageManagersEnv, "none")
+
 	// Toggle is now a no-op that prints to stdout, so we just check it doesn't error
 	err := Toggle("bash", true)
 	if err != nil {
@@ -106,3 +112,27 @@ func TestCheckDependencies(t *testing.T) {
 		}
 	}
 }
+
+func TestToolPackageFor(t *testing.T) {
+	tool := Tool{Name: "Demo", Binary: "demo", Pkg: "demo-brew", Packages: map[string]string{"apt": "demo-deb", "dnf": ""}}
+
+	tests := []struct {
+		backend string
+		wantPkg string
+		wantOK  bool
+	}{
+		{"brew", "demo-brew", true},
+		{"apt", "demo-deb", true},
+		{"dnf", "", false},
+		{"pacman", "demo-brew", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.backend, func(t *testing.T) {
+			pkg, ok := tool.PackageFor(tt.backend)
+			if pkg != tt.wantPkg || ok != tt.wantOK {
+				t.Errorf("PackageFor(%s) = (%q, %v), want (%q, %v)", tt.backend, pkg, ok, tt.wantPkg, tt.wantOK)
+			}
+		})
+	}
+}
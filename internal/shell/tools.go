@@ -13,6 +13,16 @@ type Tool struct {
 	Pkg         string // Homebrew package name
 	Default     bool   // Whether enabled by default
 	ShellDefaults map[string]bool // Per-shell default overrides
+	Packages      map[string]string // Per-backend package names; an empty name means unavailable
+}
+
+// PackageFor returns the package name to install this tool with on the given backend.
+// Backends without an override use the Homebrew package name.
+func (t Tool) PackageFor(backend string) (string, bool) {
+	if pkg, ok := t.Packages[backend]; ok {
+		return pkg, pkg != ""
+	}
+	return t.Pkg, true
 }
 
 // GetEnvVar returns the environment variable name for this tool
@@ -28,8 +38,8 @@ var Tools = []Tool{
 	{Name: "Atuin", Description: "Magical shell history", Binary: "atuin", Pkg: "atuin", Default: false, ShellDefaults: map[string]bool{"zsh": true, "fish": true}},
 	{Name: "Starship", Description: "The minimal, blazing-fast, and infinitely customizable prompt", Binary: "starship", Pkg: "starship", Default: true},
 	{Name: "Zoxide", Description: "A smarter cd command", Binary: "zoxide", Pkg: "zoxide", Default: true},
-	{Name: "UutilsCoreutils", Description: "Rust rewrite of GNU coreutils", Binary: "hashsum", Pkg: "uutils-coreutils", Default: true},
-	{Name: "UutilsFindutils", Description: "Rust rewrite of GNU findutils", Binary: "ufind", Pkg: "uutils-findutils", Default: true},
-	{Name: "UutilsDiffutils", Description: "Rust rewrite of GNU diffutils", Binary: "udiffutils", Pkg: "uutils-diffutils", Default: true},
+	{Name: "UutilsCoreutils", Description: "Rust rewrite of GNU coreutils", Binary: "hashsum", Pkg: "uutils-coreutils", Default: true, Packages: map[string]string{"apt": "rust-coreutils"}},
+	{Name: "UutilsFindutils", Description: "Rust rewrite of GNU findutils", Binary: "ufind", Pkg: "uutils-findutils", Default: true, Packages: map[string]string{"apt": ""}},
+	{Name: "UutilsDiffutils", Description: "Rust rewrite of GNU diffutils", Binary: "udiffutils", Pkg: "uutils-diffutils", Default: true, Packages: map[string]string{"apt": "", "dnf": ""}},
 	{Name: "Carapace", Description: "Multi-shell multi-command argument completer", Binary: "carapace", Pkg: "carapace", Default: false},
 }
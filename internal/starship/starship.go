@@ -1,11 +1,16 @@
 package starship
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 
+	"github.com/hanthor/bluefin-cli/internal/env"
+	"github.com/hanthor/bluefin-cli/internal/httpclient"
+	"github.com/hanthor/bluefin-cli/internal/ledger"
 	"github.com/hanthor/bluefin-cli/internal/tui"
 )
 
@@ -18,6 +23,33 @@ var (
 	lookPath = exec.LookPath
 )
 
+// installedVersion parses `starship --version`, whose first line is "starship <version>"
+var installedVersion = func() string {
+	out, err := exec.Command("starship", "--version").Output()
+	if err != nil {
+		return ""
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) < 2 {
+		return ""
+	}
+	return fields[1]
+}
+
+// record adds the Starship install to the ledger; failing to record does not fail the install
+func record(backend string) {
+	entry := ledger.Entry{
+		Action:  ledger.ActionInstall,
+		Package: "starship",
+		Backend: backend,
+		Version: installedVersion(),
+		Tool:    "Starship",
+	}
+	if err := ledger.Record(entry); err != nil {
+		fmt.Println(tui.WarningStyle.Render(fmt.Sprintf("Warning: failed to record Starship install: %v", err)))
+	}
+}
+
 // Install downloads and installs Starship
 func Install() error {
 	// Check if already installed
@@ -39,11 +71,12 @@ func Install() error {
 		}
 
 		fmt.Println(tui.SuccessStyle.Render("✓ Starship installed successfully!"))
+		record("brew")
 		return nil
 	}
 
 	// Fallback to official installer
-	cmd := execCommand("sh", "-c", "curl -sS https://starship.rs/install.sh | sh -s -- -y")
+	cmd := execCommand("sh", "-c", httpclient.CurlCommand("https://starship.rs/install.sh", "-sS")+" | sh -s -- -y")
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
@@ -52,21 +85,58 @@ func Install() error {
 	}
 
 	fmt.Println(tui.SuccessStyle.Render("✓ Starship installed successfully!"))
+	// The upstream script has no uninstaller, so this entry is for auditing only
+	record("starship.rs")
 	return nil
 }
 
-// ApplyTheme applies a Starship preset theme
-func ApplyTheme(themeName string) error {
+// ConfigPath returns the Starship config file, ~/.config/starship.toml
+func ConfigPath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
+		return "", fmt.Errorf("failed to get home directory: %w", err)
 	}
+	return filepath.Join(homeDir, ".config", "starship.toml"), nil
+}
 
-	configDir := filepath.Join(homeDir, ".config")
-	starshipConfig := filepath.Join(configDir, "starship.toml")
+// ReadConfig returns the contents of starship.toml, or "" if there is none
+func ReadConfig() string {
+	path, err := ConfigPath()
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// WriteConfig replaces starship.toml with content, e.g. one saved in a profile, and
+// remembers preset as the applied preset
+func WriteConfig(content, preset string) error {
+	path, err := ConfigPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := env.WriteFileAtomic(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write Starship config: %w", err)
+	}
+	return savePreset(preset)
+}
+
+// ApplyTheme applies a Starship preset theme
+func ApplyTheme(themeName string) error {
+	starshipConfig, err := ConfigPath()
+	if err != nil {
+		return err
+	}
 
 	// Ensure config directory exists
-	if err := os.MkdirAll(configDir, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(starshipConfig), 0755); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
@@ -80,5 +150,69 @@ func ApplyTheme(themeName string) error {
 		return fmt.Errorf("failed to apply theme: %w", err)
 	}
 
+	if err := savePreset(themeName); err != nil {
+		fmt.Println(tui.WarningStyle.Render(fmt.Sprintf("Warning: failed to remember Starship preset: %v", err)))
+	}
+
 	return nil
 }
+
+// CheckPreset returns an error unless Starship is installed and knows the preset, so callers
+// can validate a preset before changing anything else
+func CheckPreset(themeName string) error {
+	if _, err := lookPath("starship"); err != nil {
+		return fmt.Errorf("starship is not installed, cannot apply preset %s", themeName)
+	}
+	out, err := execCommand("starship", "preset", "--list").Output()
+	if err != nil {
+		return fmt.Errorf("failed to list Starship presets: %w", err)
+	}
+	for _, name := range strings.Fields(string(out)) {
+		if name == themeName {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown Starship preset: %s", themeName)
+}
+
+// presetConfig is starship.json in the config directory, remembering the applied preset
+type presetConfig struct {
+	Preset string `json:"preset"`
+}
+
+func presetPath() (string, error) {
+	dir, err := env.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "starship.json"), nil
+}
+
+// Preset returns the name of the last preset applied with ApplyTheme, or "" if none was
+func Preset() string {
+	path, err := presetPath()
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	var cfg presetConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return ""
+	}
+	return cfg.Preset
+}
+
+func savePreset(name string) error {
+	dir, err := env.EnsureConfigDir()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(presetConfig{Preset: name}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return env.WriteFileAtomic(filepath.Join(dir, "starship.json"), data, 0644)
+}
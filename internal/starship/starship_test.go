@@ -5,6 +5,8 @@ import (
 	"os/exec"
 	"strings"
 	"testing"
+
+	"github.com/hanthor/bluefin-cli/internal/ledger"
 )
 
 func TestInstall(t *testing.T) {
@@ -12,11 +14,15 @@ func TestInstall(t *testing.T) {
 	origExecCommand := execCommand
 	origRunCommand := runCommand
 	origLookPath := lookPath
+	origInstalledVersion := installedVersion
 	defer func() {
 		execCommand = origExecCommand
 		runCommand = origRunCommand
 		lookPath = origLookPath
+		installedVersion = origInstalledVersion
 	}()
+	installedVersion = func() string { return "1.21.1" }
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
 
 	tests := []struct {
 		name        string
@@ -155,6 +161,39 @@ func TestInstall(t *testing.T) {
 	}
 }
 
+func TestInstallRecordsLedger(t *testing.T) {
+	origExecCommand, origRunCommand, origLookPath, origInstalledVersion := execCommand, runCommand, lookPath, installedVersion
+	defer func() {
+		execCommand, runCommand, lookPath, installedVersion = origExecCommand, origRunCommand, origLookPath, origInstalledVersion
+	}()
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	lookPath = func(file string) (string, error) {
+		if file == "brew" {
+			return "/usr/bin/brew", nil
+		}
+		return "", fmt.Errorf("not found")
+	}
+	execCommand = func(name string, arg ...string) *exec.Cmd { return exec.Command("true") }
+	runCommand = func(cmd *exec.Cmd) error { return nil }
+	installedVersion = func() string { return "1.21.1" }
+
+	if err := Install(); err != nil {
+		t.Fatalf("Install() failed: %v", err)
+	}
+
+	entries, err := ledger.Installed()
+	if err != nil {
+		t.Fatalf("ledger.Installed() failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected one ledger entry, got %+v", entries)
+	}
+	if e := entries[0]; e.Package != "starship" || e.Backend != "brew" || e.Version != "1.21.1" || e.Tool != "Starship" {
+		t.Errorf("Unexpected ledger entry: %+v", e)
+	}
+}
+
 func TestApplyTheme(t *testing.T) {
 	// Backup and restore original variables
 	origExecCommand := execCommand
@@ -163,6 +202,8 @@ func TestApplyTheme(t *testing.T) {
 		execCommand = origExecCommand
 		runCommand = origRunCommand
 	}()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("HOMEBREW_PREFIX", "")
 
 	tests := []struct {
 		name        string
@@ -227,7 +268,37 @@ func TestApplyTheme(t *testing.T) {
 				if !foundOutputFlag {
 					t.Error("Expected -o flag in command")
 				}
+
+				if got := Preset(); got != tt.theme {
+					t.Errorf("Preset() = %q, want %q", got, tt.theme)
+				}
 			}
 		})
 	}
 }
+
+func TestCheckPreset(t *testing.T) {
+	origExecCommand := execCommand
+	origLookPath := lookPath
+	defer func() {
+		execCommand = origExecCommand
+		lookPath = origLookPath
+	}()
+
+	execCommand = func(name string, arg ...string) *exec.Cmd {
+		return exec.Command("printf", "pure-preset\ntokyo-night\n")
+	}
+	lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+
+	if err := CheckPreset("tokyo-night"); err != nil {
+		t.Errorf("Expected a known preset to pass, got %v", err)
+	}
+	if err := CheckPreset("no-such-preset"); err == nil {
+		t.Error("Expected an unknown preset to be rejected")
+	}
+
+	lookPath = func(file string) (string, error) { return "", fmt.Errorf("not found") }
+	if err := CheckPreset("tokyo-night"); err == nil {
+		t.Error("Expected an error without Starship")
+	}
+}
@@ -8,7 +8,12 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/hanthor/bluefin-cli/internal/config"
+	"github.com/hanthor/bluefin-cli/internal/install"
+	"github.com/hanthor/bluefin-cli/internal/ledger"
 	"github.com/hanthor/bluefin-cli/internal/motd"
+	"github.com/hanthor/bluefin-cli/internal/pkgmgr"
+	"github.com/hanthor/bluefin-cli/internal/policy"
 	"github.com/hanthor/bluefin-cli/internal/shell"
 )
 
@@ -86,16 +91,23 @@ func Show() error {
 
 	// MOTD status
 	leftCol += labelStyle.Render("Message of the Day:") + "\n"
-	motdStatus := motd.CheckStatus()
+	motdStatus := motd.ShellStatuses()
 	for _, s := range installedShells {
 		status := "disabled"
 		style := disabledStyle
 		symbol := "✗"
 
-		if motdStatus[s] {
+		switch st := motdStatus[s]; {
+		case st.Legacy:
+			status = "enabled (legacy line, run 'bluefin-cli motd toggle " + s + " on' to migrate)"
+			style = enabledStyle
+			symbol = "✓"
+		case st.Shown():
 			status = "enabled"
 			style = enabledStyle
 			symbol = "✓"
+		case st.Enabled:
+			status = "enabled, but the shell experience is off"
 		}
 
 		leftCol += fmt.Sprintf("  %s %s: %s\n",
@@ -104,6 +116,8 @@ func Show() error {
 			style.Render(status))
 	}
 
+	leftCol += policySummary()
+
 	// --- Right Column ---
 	var rightCol string
 
@@ -150,6 +164,34 @@ func Show() error {
 		rightCol += "    Install from: https://brew.sh\n"
 	}
 
+	// Flatpak status
+	if counts, err := install.CountFlatpaks(); err == nil {
+		user, system := counts[install.FlatpakUser], counts[install.FlatpakSystem]
+		rightCol += fmt.Sprintf("  %s Flatpak: %s\n",
+			enabledStyle.Render("✓"),
+			enabledStyle.Render(fmt.Sprintf("%d apps", user+system)))
+		rightCol += fmt.Sprintf("    user: %d, system: %d\n", user, system)
+	} else if install.IsLinux() {
+		rightCol += fmt.Sprintf("  %s Flatpak: %s\n",
+			disabledStyle.Render("✗"),
+			disabledStyle.Render("not installed"))
+	}
+
+	// Other package backends
+	settings, _ := config.Load()
+	for _, pm := range pkgmgr.Detected(settings.PackageManagers) {
+		if pm.Name() == "brew" || strings.HasPrefix(pm.Name(), "flatpak") {
+			continue
+		}
+		rightCol += fmt.Sprintf("  %s %s: %s\n",
+			enabledStyle.Render("✓"),
+			pm.Name(),
+			enabledStyle.Render("available"))
+	}
+	rightCol += fmt.Sprintf("    Preference: %s\n", strings.Join(settings.PackageManagers, " > "))
+
+	rightCol += bundleCoverage()
+
 	// Combine columns with padding
 	formatted := lipgloss.JoinHorizontal(lipgloss.Top,
 		lipgloss.NewStyle().Width(40).Render(leftCol),
@@ -160,3 +202,81 @@ func Show() error {
 
 	return nil
 }
+
+// bundleCoverage reports how much of each bundle bluefin-cli has installed is still present,
+// from the cached Brewfiles so status stays fast and works offline
+func bundleCoverage() string {
+	installed, err := ledger.Installed()
+	if err != nil {
+		return ""
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, e := range installed {
+		if e.Bundle == "" || e.Bundle == ledger.WallpapersBundle || seen[e.Bundle] {
+			continue
+		}
+		seen[e.Bundle] = true
+		names = append(names, e.Bundle)
+	}
+
+	var lines string
+	for _, report := range install.CachedCoverage(names) {
+		style := enabledStyle
+		if report.Percent() < 100 {
+			style = disabledStyle
+		}
+		lines += fmt.Sprintf("  %s %s: %s\n",
+			style.Render("●"),
+			report.Bundle,
+			style.Render(fmt.Sprintf("%d%% (%d/%d)", report.Percent(), len(report.Items)-report.Count(install.StateMissing), len(report.Items))))
+	}
+
+	if lines == "" {
+		return ""
+	}
+	return "\n" + labelStyle.Render("Bundles:") + "\n" + lines
+}
+
+// policySummary lists the policy files in effect and the settings they control, with the
+// effective value and whether the user overrides it
+func policySummary() string {
+	pol, err := policy.Load()
+	if err != nil {
+		return "\n" + labelStyle.Render("Policy:") + "\n  " + disabledStyle.Render(err.Error()) + "\n"
+	}
+	if len(pol.Files) == 0 {
+		return ""
+	}
+
+	lines := "\n" + labelStyle.Render("Policy:") + "\n"
+	for _, file := range pol.Files {
+		lines += fmt.Sprintf("  %s\n", file)
+	}
+	if len(pol.AllowedBundles) > 0 {
+		lines += fmt.Sprintf("  allowed bundles: %s\n", strings.Join(pol.AllowedBundles, ", "))
+	}
+
+	var values []policy.Value
+	if v, err := config.Effective(); err == nil {
+		values = append(values, v...)
+	}
+	if v, err := shell.Effective(filepath.Base(os.Getenv("SHELL"))); err == nil {
+		values = append(values, v...)
+	}
+	if v, err := motd.Effective(); err == nil {
+		values = append(values, v...)
+	}
+	for _, v := range values {
+		if _, set := pol.Settings[v.Key]; !set {
+			continue
+		}
+		style := enabledStyle
+		if v.Source != policy.SourcePolicy && v.Source != policy.SourceLocked {
+			style = disabledStyle
+		}
+		lines += fmt.Sprintf("  %s = %s %s\n", v.Key, v.String(), style.Render("("+v.Source+")"))
+	}
+	return lines
+}
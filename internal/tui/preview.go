@@ -0,0 +1,182 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ImageProtocol is a terminal graphics protocol used for image previews
+type ImageProtocol string
+
+const (
+	ProtocolNone  ImageProtocol = ""
+	ProtocolKitty ImageProtocol = "kitty"
+	ProtocolSixel ImageProtocol = "sixel"
+)
+
+const (
+	kittyChunkSize = 4096
+	previewMaxSize = 640
+)
+
+// DetectImageProtocol guesses the graphics protocol supported by the terminal from
+// its environment. Sixel previews need img2sixel or chafa to encode the image.
+func DetectImageProtocol() ImageProtocol {
+	term := os.Getenv("TERM")
+	program := os.Getenv("TERM_PROGRAM")
+
+	if term == "xterm-kitty" || os.Getenv("KITTY_WINDOW_ID") != "" ||
+		program == "WezTerm" || program == "ghostty" || term == "xterm-ghostty" {
+		return ProtocolKitty
+	}
+
+	if strings.Contains(term, "sixel") || strings.HasPrefix(term, "foot") || term == "mlterm" ||
+		program == "iTerm.app" || os.Getenv("KONSOLE_VERSION") != "" {
+		if sixelEncoder() != "" {
+			return ProtocolSixel
+		}
+	}
+
+	return ProtocolNone
+}
+
+// PreviewImage draws the image inline in roughly cols x rows terminal cells.
+// It returns an error when the terminal has no supported graphics protocol.
+func PreviewImage(w io.Writer, path string, cols, rows int) error {
+	switch DetectImageProtocol() {
+	case ProtocolKitty:
+		return previewKitty(w, path, cols, rows)
+	case ProtocolSixel:
+		return previewSixel(w, path, cols, rows)
+	}
+	return fmt.Errorf("terminal does not support image previews")
+}
+
+func previewKitty(w io.Writer, path string, cols, rows int) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if errors.Is(err, image.ErrFormat) {
+		// JPEG XL, WebP, AVIF and SVG wallpapers need an external decoder
+		return previewKittyExternal(w, path, cols, rows)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return writeKitty(w, img, cols, rows)
+}
+
+// previewKittyExternal converts an image the standard library can't decode to PNG with
+// ImageMagick, or lets chafa draw it
+func previewKittyExternal(w io.Writer, path string, cols, rows int) error {
+	if _, err := exec.LookPath("magick"); err == nil {
+		// [0] takes the first frame of animations
+		out, err := exec.Command("magick", path+"[0]", "-thumbnail", fmt.Sprintf("%dx%d>", previewMaxSize, previewMaxSize), "png:-").Output()
+		if err != nil {
+			return fmt.Errorf("failed to convert %s: %w", path, err)
+		}
+		img, err := png.Decode(bytes.NewReader(out))
+		if err != nil {
+			return fmt.Errorf("failed to decode %s: %w", path, err)
+		}
+		return writeKitty(w, img, cols, rows)
+	}
+	if _, err := exec.LookPath("chafa"); err == nil {
+		cmd := exec.Command("chafa", "--format=kitty", fmt.Sprintf("--size=%dx%d", cols, rows), path)
+		cmd.Stdout = w
+		return cmd.Run()
+	}
+	return fmt.Errorf("no decoder for %s images (install ImageMagick or chafa)", strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+// writeKitty transmits the image with the kitty graphics protocol
+func writeKitty(w io.Writer, img image.Image, cols, rows int) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, downscale(img, previewMaxSize)); err != nil {
+		return err
+	}
+	payload := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	// Transmit and display in chunks; m=1 marks that more chunks follow
+	for first := true; len(payload) > 0; first = false {
+		chunk := payload
+		if len(chunk) > kittyChunkSize {
+			chunk = chunk[:kittyChunkSize]
+		}
+		payload = payload[len(chunk):]
+
+		more := 0
+		if len(payload) > 0 {
+			more = 1
+		}
+		if first {
+			fmt.Fprintf(w, "\x1b_Ga=T,f=100,c=%d,r=%d,m=%d;%s\x1b\\", cols, rows, more, chunk)
+		} else {
+			fmt.Fprintf(w, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	fmt.Fprintln(w)
+	return nil
+}
+
+func previewSixel(w io.Writer, path string, cols, rows int) error {
+	var cmd *exec.Cmd
+	switch sixelEncoder() {
+	case "chafa":
+		cmd = exec.Command("chafa", "--format=sixels", fmt.Sprintf("--size=%dx%d", cols, rows), path)
+	case "img2sixel":
+		cmd = exec.Command("img2sixel", fmt.Sprintf("--width=%d", previewMaxSize), path)
+	default:
+		return fmt.Errorf("no sixel encoder found (install chafa or libsixel)")
+	}
+	cmd.Stdout = w
+	return cmd.Run()
+}
+
+func sixelEncoder() string {
+	for _, bin := range []string{"chafa", "img2sixel"} {
+		if _, err := exec.LookPath(bin); err == nil {
+			return bin
+		}
+	}
+	return ""
+}
+
+// downscale shrinks the image with nearest-neighbour sampling so its longest side
+// is at most max pixels; wallpapers are often 4K and would be slow to transmit
+func downscale(src image.Image, max int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= max && h <= max {
+		return src
+	}
+
+	scale := float64(max) / float64(w)
+	if h > w {
+		scale = float64(max) / float64(h)
+	}
+	dw, dh := int(float64(w)*scale), int(float64(h)*scale)
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		sy := b.Min.Y + int(float64(y)/scale)
+		for x := 0; x < dw; x++ {
+			dst.Set(x, y, src.At(b.Min.X+int(float64(x)/scale), sy))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,102 @@
+package wallpaper
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/hanthor/bluefin-cli/internal/tui"
+)
+
+var (
+	// For testing
+	execCommand = exec.Command
+	lookPath    = exec.LookPath
+)
+
+// Desktop identifies how the wallpaper is set on this machine
+type Desktop string
+
+const (
+	DesktopGnome   Desktop = "gnome"
+	DesktopKDE     Desktop = "kde"
+	DesktopMacOS   Desktop = "macos"
+	DesktopUnknown Desktop = "unknown"
+)
+
+// DetectDesktop inspects the platform and XDG_CURRENT_DESKTOP
+func DetectDesktop() Desktop {
+	if runtime.GOOS == "darwin" {
+		return DesktopMacOS
+	}
+
+	current := strings.ToUpper(os.Getenv("XDG_CURRENT_DESKTOP"))
+	switch {
+	case strings.Contains(current, "GNOME"), strings.Contains(current, "UNITY"), strings.Contains(current, "BUDGIE"):
+		return DesktopGnome
+	case strings.Contains(current, "KDE"):
+		return DesktopKDE
+	}
+	return DesktopUnknown
+}
+
+// Apply sets the wallpaper to an image file or the next image of a collection
+func Apply(target string) error {
+	image, err := Resolve(target)
+	if err != nil {
+		return err
+	}
+
+	if err := setWallpaper(DetectDesktop(), image); err != nil {
+		return err
+	}
+
+	if err := saveState(state{Current: image}); err != nil {
+		fmt.Println(tui.WarningStyle.Render(fmt.Sprintf("Warning: failed to save wallpaper state: %v", err)))
+	}
+
+	fmt.Println(tui.SuccessStyle.Render(fmt.Sprintf("✓ Wallpaper set to %s", image)))
+	return nil
+}
+
+func setWallpaper(desktop Desktop, image string) error {
+	switch desktop {
+	case DesktopGnome:
+		uri := (&url.URL{Scheme: "file", Path: image}).String()
+		for _, key := range []string{"picture-uri", "picture-uri-dark"} {
+			if err := run("gsettings", "set", "org.gnome.desktop.background", key, uri); err != nil {
+				return err
+			}
+		}
+		return run("gsettings", "set", "org.gnome.desktop.background", "picture-options", "zoom")
+	case DesktopKDE:
+		return run("plasma-apply-wallpaperimage", image)
+	case DesktopMacOS:
+		script := fmt.Sprintf(`tell application "System Events" to tell every desktop to set picture to %q`, image)
+		return run("osascript", "-e", script)
+	}
+
+	// Other desktops: try common standalone wallpaper setters
+	if _, err := lookPath("swww"); err == nil {
+		return run("swww", "img", image)
+	}
+	if _, err := lookPath("feh"); err == nil {
+		return run("feh", "--bg-fill", image)
+	}
+	return fmt.Errorf("don't know how to set the wallpaper on this desktop; the image is at %s", image)
+}
+
+func run(name string, args ...string) error {
+	if _, err := lookPath(name); err != nil {
+		return fmt.Errorf("%s not found", name)
+	}
+	cmd := execCommand(name, args...)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package wallpaper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hanthor/bluefin-cli/internal/tui"
+)
+
+const rotationUnit = "bluefin-cli-wallpaper"
+
+// systemdUserDir returns where user units are written
+var systemdUserDir = func() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "systemd", "user"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "systemd", "user"), nil
+}
+
+// EnableRotation installs a systemd user timer that applies the next image of the
+// collection every interval
+func EnableRotation(collection string, interval time.Duration) error {
+	if interval < time.Minute {
+		return fmt.Errorf("rotation interval must be at least one minute")
+	}
+	if _, err := lookPath("systemctl"); err != nil {
+		return fmt.Errorf("wallpaper rotation requires systemd")
+	}
+	if _, err := FindCollection(collection); err != nil {
+		return err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate bluefin-cli binary: %w", err)
+	}
+
+	dir, err := systemdUserDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	service, timer := rotationUnits(exe, collection, os.Getenv("XDG_CURRENT_DESKTOP"), interval)
+	if err := os.WriteFile(filepath.Join(dir, rotationUnit+".service"), []byte(service), 0644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, rotationUnit+".timer"), []byte(timer), 0644); err != nil {
+		return err
+	}
+
+	if err := run("systemctl", "--user", "daemon-reload"); err != nil {
+		return err
+	}
+	// Restart so a changed interval takes effect immediately
+	if err := run("systemctl", "--user", "enable", rotationUnit+".timer"); err != nil {
+		return err
+	}
+	if err := run("systemctl", "--user", "restart", rotationUnit+".timer"); err != nil {
+		return err
+	}
+
+	fmt.Println(tui.SuccessStyle.Render(fmt.Sprintf("✓ Rotating %s every %s", collection, interval)))
+	return nil
+}
+
+// DisableRotation stops the rotation timer and removes its units
+func DisableRotation() error {
+	dir, err := systemdUserDir()
+	if err != nil {
+		return err
+	}
+
+	timerPath := filepath.Join(dir, rotationUnit+".timer")
+	if _, err := os.Stat(timerPath); os.IsNotExist(err) {
+		fmt.Println(tui.InfoStyle.Render("Wallpaper rotation is not enabled"))
+		return nil
+	}
+
+	if err := run("systemctl", "--user", "disable", "--now", rotationUnit+".timer"); err != nil {
+		return err
+	}
+	os.Remove(timerPath)
+	os.Remove(filepath.Join(dir, rotationUnit+".service"))
+	if err := run("systemctl", "--user", "daemon-reload"); err != nil {
+		return err
+	}
+
+	fmt.Println(tui.SuccessStyle.Render("✓ Wallpaper rotation disabled"))
+	return nil
+}
+
+// rotationUnits renders the service and timer unit files
+func rotationUnits(exe, collection, desktop string, interval time.Duration) (string, string) {
+	var env string
+	if desktop != "" {
+		// The user manager doesn't always inherit the session's desktop, which Apply relies on
+		env = fmt.Sprintf("Environment=XDG_CURRENT_DESKTOP=%s\n", desktop)
+	}
+
+	service := fmt.Sprintf(`[Unit]
+Description=Rotate desktop wallpaper from %[1]s (bluefin-cli)
+
+[Service]
+Type=oneshot
+%[2]sExecStart=%[3]s wallpapers apply %[4]s
+`, collection, env, quoteArg(exe), quoteArg(collection))
+
+	timer := fmt.Sprintf(`[Unit]
+Description=Rotate desktop wallpaper every %[1]s (bluefin-cli)
+
+[Timer]
+OnActiveSec=%[2]ds
+OnUnitActiveSec=%[2]ds
+
+[Install]
+WantedBy=timers.target
+`, interval, int(interval.Seconds()))
+
+	return service, timer
+}
+
+// quoteArg quotes an ExecStart argument for systemd when it contains spaces
+func quoteArg(s string) string {
+	if !strings.ContainsAny(s, " \t\"") {
+		return s
+	}
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
@@ -0,0 +1,162 @@
+package wallpaper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hanthor/bluefin-cli/internal/env"
+)
+
+// Collection is a directory of installed wallpaper images, usually created by a wallpaper cask
+type Collection struct {
+	Name   string
+	Path   string
+	Images []string
+}
+
+var imageExtensions = []string{".jpg", ".jpeg", ".png", ".webp", ".jxl", ".avif", ".svg"}
+
+// searchDirs returns the directories wallpaper casks install collections into
+var searchDirs = func() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	if runtime.GOOS == "darwin" {
+		return []string{filepath.Join(home, "Library", "Desktop Pictures")}
+	}
+	return []string{
+		filepath.Join(home, ".local", "share", "backgrounds"),
+		filepath.Join(home, ".local", "share", "wallpapers"),
+	}
+}
+
+// ListInstalled returns the installed wallpaper collections sorted by name.
+// A collection found in several directories (e.g. GNOME and KDE copies) is listed once.
+func ListInstalled() ([]Collection, error) {
+	var collections []Collection
+	seen := make(map[string]bool)
+
+	for _, dir := range searchDirs() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+		}
+
+		for _, entry := range entries {
+			if !entry.IsDir() || seen[entry.Name()] {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			images := findImages(path)
+			if len(images) == 0 {
+				continue
+			}
+			seen[entry.Name()] = true
+			collections = append(collections, Collection{Name: entry.Name(), Path: path, Images: images})
+		}
+	}
+
+	sort.Slice(collections, func(i, j int) bool { return collections[i].Name < collections[j].Name })
+	return collections, nil
+}
+
+// FindCollection returns the installed collection with the given name
+func FindCollection(name string) (Collection, error) {
+	collections, err := ListInstalled()
+	if err != nil {
+		return Collection{}, err
+	}
+	for _, c := range collections {
+		if c.Name == name {
+			return c, nil
+		}
+	}
+	return Collection{}, fmt.Errorf("wallpaper collection not found: %s (see 'bluefin-cli wallpapers list-installed')", name)
+}
+
+// Resolve turns an image path or collection name into the image to apply.
+// For collections it returns the image after the one applied last, so repeated
+// calls cycle through the collection.
+func Resolve(target string) (string, error) {
+	if info, err := os.Stat(target); err == nil && !info.IsDir() {
+		return filepath.Abs(target)
+	}
+
+	collection, err := FindCollection(target)
+	if err != nil {
+		return "", err
+	}
+
+	state := loadState()
+	next := 0
+	if i := slices.Index(collection.Images, state.Current); i >= 0 {
+		next = (i + 1) % len(collection.Images)
+	}
+	return collection.Images[next], nil
+}
+
+func findImages(dir string) []string {
+	var images []string
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if slices.Contains(imageExtensions, strings.ToLower(filepath.Ext(path))) {
+			images = append(images, path)
+		}
+		return nil
+	})
+	sort.Strings(images)
+	return images
+}
+
+// state remembers the wallpaper applied last
+type state struct {
+	Current string `json:"current"`
+}
+
+func statePath() (string, error) {
+	dir, err := env.GetStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "wallpaper.json"), nil
+}
+
+func loadState() state {
+	var s state
+	path, err := statePath()
+	if err != nil {
+		return s
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &s)
+	}
+	return s
+}
+
+func saveState(s state) error {
+	if _, err := env.EnsureStateDir(); err != nil {
+		return err
+	}
+	path, err := statePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
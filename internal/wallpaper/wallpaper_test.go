@@ -0,0 +1,146 @@
+package wallpaper
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setupCollections creates fake wallpaper directories and points searchDirs at them
+func setupCollections(t *testing.T) (string, string) {
+	t.Helper()
+	root := t.TempDir()
+	backgrounds := filepath.Join(root, "backgrounds")
+	wallpapers := filepath.Join(root, "wallpapers")
+
+	files := []string{
+		filepath.Join(backgrounds, "bluefin", "01-day.jxl"),
+		filepath.Join(backgrounds, "bluefin", "02-night.jxl"),
+		filepath.Join(backgrounds, "bluefin", "bluefin.xml"),
+		filepath.Join(backgrounds, "aurora", "aurora.png"),
+		filepath.Join(backgrounds, "empty", "README.md"),
+		filepath.Join(wallpapers, "bluefin", "contents", "images", "3840x2160.jxl"),
+		filepath.Join(wallpapers, "kde-only", "contents", "images", "1920x1080.jpg"),
+	}
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(f), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(f, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	origSearchDirs := searchDirs
+	searchDirs = func() []string { return []string{backgrounds, wallpapers} }
+	t.Cleanup(func() { searchDirs = origSearchDirs })
+
+	t.Setenv("XDG_STATE_HOME", filepath.Join(root, "state"))
+	return backgrounds, wallpapers
+}
+
+func TestListInstalled(t *testing.T) {
+	backgrounds, _ := setupCollections(t)
+
+	collections, err := ListInstalled()
+	if err != nil {
+		t.Fatalf("ListInstalled() failed: %v", err)
+	}
+
+	var names []string
+	for _, c := range collections {
+		names = append(names, c.Name)
+	}
+	if strings.Join(names, ",") != "aurora,bluefin,kde-only" {
+		t.Fatalf("Unexpected collections: %v", names)
+	}
+
+	bluefin := collections[1]
+	if bluefin.Path != filepath.Join(backgrounds, "bluefin") {
+		t.Errorf("Expected GNOME copy of bluefin to win, got %s", bluefin.Path)
+	}
+	if len(bluefin.Images) != 2 {
+		t.Errorf("Expected 2 images in bluefin, got %v", bluefin.Images)
+	}
+}
+
+func TestResolveCyclesThroughCollection(t *testing.T) {
+	backgrounds, _ := setupCollections(t)
+	day := filepath.Join(backgrounds, "bluefin", "01-day.jxl")
+	night := filepath.Join(backgrounds, "bluefin", "02-night.jxl")
+
+	for i, want := range []string{day, night, day} {
+		got, err := Resolve("bluefin")
+		if err != nil {
+			t.Fatalf("Resolve() failed: %v", err)
+		}
+		if got != want {
+			t.Errorf("Step %d: expected %s, got %s", i, want, got)
+		}
+		if err := saveState(state{Current: got}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := Resolve("missing"); err == nil {
+		t.Error("Expected error for unknown collection")
+	}
+}
+
+func TestApplyGnome(t *testing.T) {
+	backgrounds, _ := setupCollections(t)
+	t.Setenv("XDG_CURRENT_DESKTOP", "ubuntu:GNOME")
+
+	origExecCommand, origLookPath := execCommand, lookPath
+	defer func() { execCommand, lookPath = origExecCommand, origLookPath }()
+
+	var calls []string
+	lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	execCommand = func(name string, arg ...string) *exec.Cmd {
+		calls = append(calls, name+" "+strings.Join(arg, " "))
+		return exec.Command("true")
+	}
+
+	if err := Apply("aurora"); err != nil {
+		t.Fatalf("Apply() failed: %v", err)
+	}
+
+	uri := "file://" + filepath.Join(backgrounds, "aurora", "aurora.png")
+	want := []string{
+		"gsettings set org.gnome.desktop.background picture-uri " + uri,
+		"gsettings set org.gnome.desktop.background picture-uri-dark " + uri,
+		"gsettings set org.gnome.desktop.background picture-options zoom",
+	}
+	if strings.Join(calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected commands:\n%s", strings.Join(calls, "\n"))
+	}
+
+	if loadState().Current != filepath.Join(backgrounds, "aurora", "aurora.png") {
+		t.Error("Expected applied wallpaper to be saved in state")
+	}
+}
+
+func TestRotationUnits(t *testing.T) {
+	service, timer := rotationUnits("/home/me/bin/bluefin-cli", "bluefin", "GNOME", 30*time.Minute)
+
+	if !strings.Contains(service, "ExecStart=/home/me/bin/bluefin-cli wallpapers apply bluefin") {
+		t.Errorf("Service missing ExecStart:\n%s", service)
+	}
+	if !strings.Contains(service, "Environment=XDG_CURRENT_DESKTOP=GNOME") {
+		t.Errorf("Service missing desktop environment:\n%s", service)
+	}
+	if !strings.Contains(timer, "OnUnitActiveSec=1800s") {
+		t.Errorf("Timer missing interval:\n%s", timer)
+	}
+	if !strings.Contains(timer, "WantedBy=timers.target") {
+		t.Errorf("Timer missing install section:\n%s", timer)
+	}
+
+	service, _ = rotationUnits("/opt/my apps/bluefin-cli", "my walls", "", time.Hour)
+	if !strings.Contains(service, `ExecStart="/opt/my apps/bluefin-cli" wallpapers apply "my walls"`) {
+		t.Errorf("Expected quoted arguments:\n%s", service)
+	}
+}